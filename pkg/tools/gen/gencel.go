@@ -0,0 +1,371 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// celKeywords translates the KCL keywords and literals to CEL
+var celKeywords = map[string]string{
+	"and":   "&&",
+	"or":    "||",
+	"in":    "in",
+	"True":  "true",
+	"False": "false",
+	"None":  "null",
+}
+
+// celMethods translates the KCL string methods to the CEL ones
+var celMethods = map[string]string{
+	"startswith": "startsWith",
+	"endswith":   "endsWith",
+	"lower":      "lowerAscii",
+	"upper":      "upperAscii",
+}
+
+// celOperators are the KCL operators which are the same in CEL
+var celOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true,
+	"(": true, ")": true, "[": true, "]": true, ",": true, ".": true,
+}
+
+// celValidationsFileName returns the name of the CEL validations file of the schema
+func celValidationsFileName(id string) string {
+	return fmt.Sprintf("%s.validations.cel", id)
+}
+
+// ExportCELValidations translates the check expressions of each schema to the CEL expressions on `self`, such as
+// the `x-kubernetes-validations` rules and the ValidatingAdmissionPolicy validations. The comparisons, membership,
+// `len`, `regex.match` and the string prefix and suffix checks are translated, and the other constructs are skipped
+// with the original KCL expression in a comment. The returned map is from the file path to the file content of the
+// schemas with any check expression, and the skipped expressions are returned as `<schema id>: <expr>`.
+func ExportCELValidations(spec *SwaggerV2Spec) (map[string][]byte, []string) {
+	files := map[string][]byte{}
+	var skipped []string
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if schema.KclExtensions == nil || len(schema.KclExtensions.XKclChecks) == 0 {
+			continue
+		}
+		var buf bytes.Buffer
+		buf.WriteString("// Auto generated by kcl-doc tool, please do not edit.\n")
+		for _, check := range schema.KclExtensions.XKclChecks {
+			buf.WriteString("\n")
+			expr, err := checkToCEL(check, schema)
+			if err != nil {
+				fmt.Fprintf(&buf, "// skipped: %s\n// reason: %s\n", checkSource(check), err)
+				skipped = append(skipped, fmt.Sprintf("%s: %s", id, checkSource(check)))
+				continue
+			}
+			if check.Message != "" {
+				fmt.Fprintf(&buf, "// message: %s\n", check.Message)
+			}
+			buf.WriteString(expr + "\n")
+		}
+		files[celValidationsFileName(id)] = buf.Bytes()
+	}
+	return files, skipped
+}
+
+// checkSource returns the KCL source of the check expression
+func checkSource(check *XKclCheck) string {
+	source := check.Expr
+	if check.Condition != "" {
+		source += " if " + check.Condition
+	}
+	return source
+}
+
+// checkToCEL translates the check expression with its condition to a CEL expression
+func checkToCEL(check *XKclCheck, schema *KclOpenAPIType) (string, error) {
+	expr, err := exprToCEL(check.Expr, schema)
+	if err != nil {
+		return "", err
+	}
+	if check.Condition == "" {
+		return expr, nil
+	}
+	cond, err := exprToCEL(check.Condition, schema)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("!(%s) || (%s)", cond, expr), nil
+}
+
+// exprToCEL translates the KCL expression to a CEL expression. The chained comparisons such as `1 <= len(a) <= 10`
+// are split to the comparisons joined with `&&`
+func exprToCEL(expr string, schema *KclOpenAPIType) (string, error) {
+	if len(splitTopLevel(expr, " and ")) == 1 && len(splitTopLevel(expr, " or ")) == 1 {
+		if comparisons := parseComparisons(expr); len(comparisons) > 1 {
+			parts := make([]string, len(comparisons))
+			for i, c := range comparisons {
+				part, err := exprToCEL(fmt.Sprintf("%s %s %s", c.Left, c.Op, c.Right), schema)
+				if err != nil {
+					return "", err
+				}
+				parts[i] = part
+			}
+			return strings.Join(parts, " && "), nil
+		}
+	}
+	tokens, err := tokenizeCheckExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	t := &celTranslator{tokens: tokens, schema: schema}
+	return t.translate(0, len(tokens))
+}
+
+// checkToken is a token of the check expression
+type checkToken struct {
+	Kind  string // ident, number, string or op
+	Value string
+}
+
+// tokenizeCheckExpr splits the check expression to the tokens
+func tokenizeCheckExpr(expr string) ([]checkToken, error) {
+	var tokens []checkToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, checkToken{Kind: "ident", Value: string(runes[i:j])})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			if j < len(runes) && unicode.IsLetter(runes[j]) {
+				return nil, fmt.Errorf("the unit literals are not supported")
+			}
+			tokens = append(tokens, checkToken{Kind: "number", Value: string(runes[i:j])})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != c {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			if len(tokens) > 0 && tokens[len(tokens)-1].Kind == "ident" && (tokens[len(tokens)-1].Value == "r" || tokens[len(tokens)-1].Value == "R") {
+				// the raw string prefix is the same in CEL
+				tokens[len(tokens)-1] = checkToken{Kind: "string", Value: "r" + string(runes[i:j+1])}
+			} else {
+				tokens = append(tokens, checkToken{Kind: "string", Value: string(runes[i : j+1])})
+			}
+			i = j + 1
+		default:
+			op := string(c)
+			if i+1 < len(runes) && celOperators[string(runes[i:i+2])] {
+				op = string(runes[i : i+2])
+			}
+			if i+1 < len(runes) && (op == "/" || op == "*") && runes[i+1] == c {
+				return nil, fmt.Errorf("the operator %s%s is not supported", op, op)
+			}
+			if !celOperators[op] {
+				return nil, fmt.Errorf("the operator %s is not supported", op)
+			}
+			tokens = append(tokens, checkToken{Kind: "op", Value: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// celTranslator translates the tokens of a check expression to CEL
+type celTranslator struct {
+	tokens []checkToken
+	schema *KclOpenAPIType
+}
+
+func (t *celTranslator) token(i int) checkToken {
+	if i < 0 || i >= len(t.tokens) {
+		return checkToken{}
+	}
+	return t.tokens[i]
+}
+
+func (t *celTranslator) isAttribute(tok checkToken) bool {
+	if tok.Kind != "ident" {
+		return false
+	}
+	_, ok := t.schema.Properties[tok.Value]
+	return ok
+}
+
+// closing returns the index of the bracket closing the one at the index
+func (t *celTranslator) closing(open int) (int, error) {
+	depth := 0
+	for i := open; i < len(t.tokens); i++ {
+		switch t.tokens[i].Value {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced brackets")
+}
+
+// translate translates the tokens in [start, end)
+func (t *celTranslator) translate(start int, end int) (string, error) {
+	var parts []string
+	for i := start; i < end; i++ {
+		tok := t.tokens[i]
+		prev := t.token(i - 1)
+		switch {
+		case tok.Kind == "ident" && prev.Value == "." && i > start:
+			// member access
+			if method, ok := celMethods[tok.Value]; ok && t.token(i+1).Value == "(" {
+				parts = append(parts, method)
+			} else {
+				parts = append(parts, tok.Value)
+			}
+		case t.isAttribute(tok):
+			// `a is None`, `a is not None`, `a == None` and `a != None` check the presence of the attribute
+			next, after := t.token(i+1), t.token(i+2)
+			switch {
+			case next.Value == "is" && after.Value == "not" && t.token(i+3).Value == "None":
+				parts = append(parts, fmt.Sprintf("has(self.%s)", tok.Value))
+				i += 3
+			case (next.Value == "is" || next.Value == "==") && after.Value == "None":
+				parts = append(parts, fmt.Sprintf("!has(self.%s)", tok.Value))
+				i += 2
+			case next.Value == "!=" && after.Value == "None":
+				parts = append(parts, fmt.Sprintf("has(self.%s)", tok.Value))
+				i += 2
+			default:
+				parts = append(parts, "self."+tok.Value)
+			}
+		case tok.Kind == "ident" && tok.Value == "len" && t.token(i+1).Value == "(":
+			parts = append(parts, "size")
+		case tok.Kind == "ident" && tok.Value == "regex" && t.token(i+1).Value == "." && t.token(i+2).Value == "match" && t.token(i+3).Value == "(":
+			// regex.match(s, pattern) -> s.matches(pattern)
+			close, err := t.closing(i + 3)
+			if err != nil {
+				return "", err
+			}
+			args, err := t.arguments(i+4, close)
+			if err != nil {
+				return "", err
+			}
+			if len(args) != 2 {
+				return "", fmt.Errorf("regex.match expects 2 arguments")
+			}
+			parts = append(parts, fmt.Sprintf("%s.matches(%s)", args[0], args[1]))
+			i = close
+		case tok.Kind == "ident" && tok.Value == "not":
+			if t.token(i+1).Value == "in" {
+				return "", fmt.Errorf("the `not in` operator is not supported")
+			}
+			if !t.isSimpleOperand(i + 1) {
+				return "", fmt.Errorf("the `not` operator is only supported on an attribute or a parenthesized expression")
+			}
+			parts = append(parts, "!")
+		case tok.Kind == "ident":
+			cel, ok := celKeywords[tok.Value]
+			if !ok {
+				return "", fmt.Errorf("%s is not supported", tok.Value)
+			}
+			parts = append(parts, cel)
+		default:
+			parts = append(parts, tok.Value)
+		}
+	}
+	return joinCELTokens(parts), nil
+}
+
+// isSimpleOperand checks if the operand at the index is a parenthesized expression, or an attribute not followed by
+// another operator than `and` and `or`, so `!` applies to the same operand as `not`
+func (t *celTranslator) isSimpleOperand(i int) bool {
+	if t.token(i).Value == "(" {
+		close, err := t.closing(i)
+		if err != nil {
+			return false
+		}
+		i = close
+	} else if !t.isAttribute(t.token(i)) {
+		return false
+	}
+	switch t.token(i + 1).Value {
+	case "", "and", "or", ")", ",":
+		return true
+	}
+	return false
+}
+
+// arguments translates the call arguments in [start, end) separated by the top level commas
+func (t *celTranslator) arguments(start int, end int) ([]string, error) {
+	var args []string
+	argStart, depth := start, 0
+	for i := start; i <= end; i++ {
+		if i < end {
+			switch t.tokens[i].Value {
+			case "(", "[":
+				depth++
+				continue
+			case ")", "]":
+				depth--
+				continue
+			case ",":
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		arg, err := t.translate(argStart, i)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		argStart = i + 1
+	}
+	return args, nil
+}
+
+// joinCELTokens joins the CEL tokens with the spaces around the binary operators
+func joinCELTokens(parts []string) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			prev := parts[i-1]
+			noSpace := p == ")" || p == "]" || p == "," || p == "." || p == "(" && !isCELBinaryOp(prev) && prev != "," ||
+				p == "[" && (prev == ")" || prev == "]" || strings.HasPrefix(prev, "self.")) ||
+				prev == "(" || prev == "[" || prev == "." || prev == "!" ||
+				prev == "-" && (i == 1 || isCELBinaryOp(parts[i-2]) || parts[i-2] == "(" || parts[i-2] == ",")
+			if !noSpace {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func isCELBinaryOp(s string) bool {
+	switch s {
+	case "&&", "||", "in", "==", "!=", "<", "<=", ">", ">=", "+", "-", "*", "/", "%":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package gen
+
+import (
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func TestCheckToCEL(t *testing.T) {
+	schema := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+		"name": {Type: String}, "host": {Type: String}, "protocol": {Type: String}, "replicas": {Type: Integer},
+		"port": {Type: Integer}, "offset": {Type: Integer}, "tls": {Type: Bool}, "hosts": {Type: Array},
+		"labels": {Type: Object},
+	})
+	cases := []struct {
+		check  XKclCheck
+		expect string
+	}{
+		{XKclCheck{Expr: "len(name) >= 1"}, "size(self.name) >= 1"},
+		{XKclCheck{Expr: "1 <= replicas <= 10"}, "1 <= self.replicas && self.replicas <= 10"},
+		{XKclCheck{Expr: `protocol in ["http", "https"]`}, `self.protocol in ["http", "https"]`},
+		{XKclCheck{Expr: `regex.match(host, r"^[a-z]+$")`}, `self.host.matches(r"^[a-z]+$")`},
+		{XKclCheck{Expr: "port > 0", Condition: "tls"}, "!(self.tls) || (self.port > 0)"},
+		{XKclCheck{Expr: `host.startswith("www.") or host.endswith(".local")`}, `self.host.startsWith("www.") || self.host.endsWith(".local")`},
+		{XKclCheck{Expr: "labels is not None"}, "has(self.labels)"},
+		{XKclCheck{Expr: "not tls or port == 443"}, "!self.tls || self.port == 443"},
+		{XKclCheck{Expr: "not (tls and port == 80)"}, "!(self.tls && self.port == 80)"},
+		{XKclCheck{Expr: "-1 < offset and len(hosts[0]) > 0"}, "-1 < self.offset && size(self.hosts[0]) > 0"},
+	}
+	for _, c := range cases {
+		got, err := checkToCEL(&c.check, schema)
+		if assert2.NoError(t, err, c.check.Expr) {
+			assert2.Equal(t, c.expect, got)
+		}
+	}
+	for _, expr := range []string{
+		"all x in hosts { x }",
+		`protocol not in ["ftp"]`,
+		"len([x for x in [host, name] if x]) == 1",
+		"not port == 80",
+		"replicas ** 2 < 100",
+		"memory <= 1Gi",
+		"typeof(port) == \"int\"",
+	} {
+		_, err := checkToCEL(&XKclCheck{Expr: expr}, schema)
+		assert2.Error(t, err, expr)
+	}
+}
+
+func TestExportCELValidations(t *testing.T) {
+	server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}, "hosts": {Type: Array}})
+	server.KclExtensions.XKclChecks = []*XKclCheck{
+		{Expr: "len(name) >= 1", Message: "name must not be empty"},
+		{Expr: "all x in hosts { x }"},
+	}
+	volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Volume": volume}}
+
+	g := newTestGenContext(t, CEL)
+	files := renderTestDoc(t, g, spec)
+	assert2.Equal(t, map[string]string{"Server.validations.cel": `// Auto generated by kcl-doc tool, please do not edit.
+
+// message: name must not be empty
+size(self.name) >= 1
+
+// skipped: all x in hosts { x }
+// reason: the operator { is not supported
+`}, files)
+	assert2.Equal(t, []string{"skipped the check expression which can not be translated to CEL: Server: all x in hosts { x }"}, g.Warnings)
+}
@@ -3,16 +3,20 @@ package gen
 import (
 	"bytes"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	htmlTmpl "html/template"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
 )
 
 //go:embed templates/doc/schemaDoc.gotmpl
@@ -50,6 +54,178 @@ type GenContext struct {
 	SchemaListDocTmpl string
 	// Template is the doc render template
 	Template *template.Template
+	// AttributeAnchors defines whether to render a permalink anchor for each schema attribute
+	AttributeAnchors bool
+	// ShowRequiredQuickRef defines whether to render a line of the required attributes and their types linked to the
+	// attribute anchors before the attributes of each schema
+	ShowRequiredQuickRef bool
+	// HideInternal defines whether to omit the internal attributes from the required quick reference line. The
+	// internal attributes are tagged with `@internal` in their docstrings or named with the `_` prefix
+	HideInternal bool
+	// AutolinkAttributes defines whether to link the inline code in descriptions which exactly matches a sibling
+	// attribute name to the anchor of that attribute. It implies AttributeAnchors
+	AutolinkAttributes bool
+	// JsonSchemaFilePerSchema defines whether to output one JSON Schema file per schema with a catalog.json when the
+	// output format is jsonschema, instead of a single bundled document
+	JsonSchemaFilePerSchema bool
+	// FilePerSchema defines whether to write each schema and each enum to its own page named by the qualified name,
+	// such as `models.Server.md`, when the output format is md or html. The package page is the index of the pages
+	// with the enums listed under an "Enums" group, and the schema links point to the pages. The enums are the type
+	// aliases of the literal union types, such as `type Protocol = "TCP" | "UDP"`. The facets are not rendered
+	FilePerSchema bool
+	// AnnotationsToFrontMatter is the annotation tags of the schema docstrings such as `category` for `@category`,
+	// which are promoted to the front matter of the schema pages when FilePerSchema is set and the output format is
+	// md. The tags are written in the order, a tag declared more than once is a list, and the absent tags are omitted
+	AnnotationsToFrontMatter []string
+	// docEnums is the enums of the attributes by enum id, and docFiles is the page file names of the schemas and
+	// enums by id, collected when FilePerSchema is set
+	docEnums map[string]*docEnum
+	docFiles map[string]string
+	// ValidateSchemaExamples defines whether to fail the generation if an `@example` instance in the schema docstring
+	// does not match the schema. If not set, the mismatches are reported as warnings
+	ValidateSchemaExamples bool
+	// ShowEvaluatedExample defines whether to evaluate the schema examples with the KCL runtime and render the results
+	// alongside the examples. The examples which fail to evaluate are rendered without the results
+	ShowEvaluatedExample bool
+	// ShowSourceFiles defines whether to render the source files section of each schema
+	ShowSourceFiles bool
+	// SourceSectionTitle is the title of the source files section, defaults to "Source Files"
+	SourceSectionTitle string
+	// SourcePathResolver rewrites the slash-separated source file paths relative to the package root before they are
+	// rendered, e.g. to map the sandbox paths to the published repository paths. The paths are rendered as is if nil
+	SourcePathResolver func(original string) string `json:"-"`
+	// EmitOperationStubs defines whether to add a stub `GET` and `PUT` path for each schema when the output format is
+	// openapi, which uses the schema as the request and response body
+	EmitOperationStubs bool
+	// OperationPathTemplate is the path template of the operation stubs, in which `{schema}` is replaced with the
+	// schema id. Defaults to DefaultOperationPathTemplate
+	OperationPathTemplate string
+	// IncludeMetrics defines whether to render the size and complexity metrics of each schema, and write a
+	// metrics.json summary of all the schemas sorted by the complexity descending
+	IncludeMetrics bool
+	// schemaMetrics is the metrics of the schemas by schema id, computed when IncludeMetrics is set
+	schemaMetrics map[string]*schemaMetrics
+	// TableShowExample defines whether to add an example column to the attribute tables, which shows a sample value
+	// of each attribute: the default value if present, else a placeholder of the type or the referenced schema name
+	TableShowExample bool
+	// ShowConstraintExamples defines whether to add a valid and an invalid example value to the descriptions of the
+	// attributes constrained by the enum members, the bounds or the `regex.match` patterns in the schema checks
+	ShowConstraintExamples bool
+	// Roots is the ids of the entry point schemas such as `models.Server`, which are exempt from the orphan schemas:
+	// the schemas which no attribute type, base schema or mixin of another schema references. If set, the orphan
+	// schemas are reported as warnings
+	Roots []string
+	// ErrorOnOrphans defines whether to fail the generation if any schema is an orphan, which no other schema
+	// references and which is not one of the Roots
+	ErrorOnOrphans bool
+	// ErrorOnTypeMismatch defines whether to fail the generation if the literal default of any attribute does not
+	// match the attribute type. If not set, the mismatches are reported as warnings
+	ErrorOnTypeMismatch bool
+	// ErrorOnInheritanceConflict defines whether to fail the generation if any attribute of a schema is redeclared
+	// with a type incompatible with the attribute type of its base schema. If not set, the conflicts are reported as
+	// warnings. The narrowed types such as the literal types of the base type are compatible
+	ErrorOnInheritanceConflict bool
+	// ShowDefaultsShape defines whether to render the YAML of each schema instantiated with only the placeholders of
+	// the required attributes and all the defaults applied. The instance is evaluated with the KCL runtime if available,
+	// otherwise the shape is approximated from the attribute defaults
+	ShowDefaultsShape bool
+	// defaultsShapes is the defaults shapes of the schemas by schema id, rendered when ShowDefaultsShape is set
+	defaultsShapes map[string]string
+	// EmitPackageReadme defines whether to write a README.md for each package into the directory mirroring the
+	// package under the target directory, with the package docstring, a badge row and the schema list
+	EmitPackageReadme bool
+	// IncludePattern is the regex of the qualified schema names such as `models.Server` to render. If set, only the
+	// matched schemas are rendered in the markdown and html docs
+	IncludePattern string
+	// ExcludePattern is the regex of the qualified schema names not to render in the markdown and html docs. The
+	// references to the excluded schemas are rendered as the plain text
+	ExcludePattern string
+	// excludedAnchors is the anchors of the schemas filtered out by the IncludePattern and ExcludePattern
+	excludedAnchors map[string]bool
+	// VerifyLinks defines whether to fail the generation if any link target in the docs is unresolved, such as the
+	// unknown schema of a `@related` tag
+	VerifyLinks bool
+	// UnresolvedLinks collects the unresolved link targets during the generation
+	UnresolvedLinks []string
+	// relatedNotes is the notes of the related schemas by the schema id and attribute name joined with a dot
+	relatedNotes map[string]string
+	// ShowElementSummary defines whether to render the first sentence of the element schema description next to the
+	// type of the list and dict attributes whose elements are schemas
+	ShowElementSummary bool
+	// schemaSummaries is the first sentences of the schema descriptions by schema id, collected when
+	// ShowElementSummary is set
+	schemaSummaries map[string]string
+	// importStatements is the import statements to reference the schemas outside the root package by schema id
+	importStatements map[string]string
+	// packageDocLinks is the doc paths of the workspace modules by package name relative to the workspace target, and
+	// packageDocDir is the directory of the module being rendered, to link the successors of the deprecated packages
+	packageDocLinks map[string]string
+	packageDocDir   string
+	// memoryOutputs collects the generated files and virtualSources is the KCL sources by file name when rendering
+	// from the sources in memory
+	memoryOutputs  *memoryOutputs
+	virtualSources map[string]string
+	// Incremental defines whether to render only the packages whose .k sources changed since the last generation,
+	// which is tracked by the content hash manifest `.kcl-doc-cache.json` in the target directory
+	Incremental bool
+	// ForceFull defines whether to render all the packages in the incremental mode regardless of the manifest
+	ForceFull bool
+	// EnableCopyButtons defines whether to add a copy-to-clipboard button to each code block of the html docs, such
+	// as the schema examples, and to each attribute type with the type expression. The script of the buttons is
+	// bundled in the page
+	EnableCopyButtons bool
+	// EnableFacets defines whether to add the filter controls of the schema stabilities and `@tag` tags to the index of
+	// the html docs. The filter script is bundled in the page
+	EnableFacets bool
+	// EmbedToolVersion defines whether to prepend a header to the generated files with the kcl-go version, the KCL
+	// version and the generation time, to trace the doc drift across the tool upgrades
+	EmbedToolVersion bool
+	// Deterministic defines whether to omit the generation time from the header, so the repeated generations of
+	// the same sources are identical
+	Deterministic bool
+	// EmitPDF defines whether to render the html docs to a single PDF as well, with a cover page, a table of contents
+	// and a page for each schema. It requires an HTML-to-PDF converter
+	EmitPDF bool
+	// PDFConverter is the path to the HTML-to-PDF converter invoked as `<converter> <input.html> <output.pdf>`.
+	// Defaults to wkhtmltopdf in the PATH
+	PDFConverter string
+	// Locale is the locale of the docs such as `zh`. If set, the descriptions are rendered with the translations in
+	// the `@<locale>` tags of the docstrings, and fall back to the untagged descriptions
+	Locale string
+	// Locales is the list of the locales to generate the docs for, each into a separate directory named by the locale
+	// under the target directory. It overrides Locale
+	Locales []string
+	// Warnings collects the warnings reported during the generation
+	Warnings []string
+	// Logger is the logger of the generation diagnostics. Defaults to printing the info messages to the stdout and
+	// the warning messages to the stderr
+	Logger Logger
+	// TechDocs defines whether to output the markdown docs in the Backstage TechDocs layout: the package page is
+	// written to docs/index.md with the front matter, and a mkdocs.yml with the nav is written next to docs/
+	TechDocs bool
+	// CollapseInherited defines whether to separate the attributes inherited from the base schema from the declared
+	// ones: they are collapsed in a <details> element for HTML, and placed in an "Inherited Attributes" section at the
+	// bottom of the schema for Markdown
+	CollapseInherited bool
+	// inheritedAttributes is the inherited attributes of the schemas by schema id, resolved when CollapseInherited is set
+	inheritedAttributes map[string]*inheritedAttributeGroup
+	// AttributeStyle is the layout of the attributes of the schemas. Defaults to Flat, and ByOwner groups the attributes
+	// by the schemas declaring them, which overrides CollapseInherited
+	AttributeStyle AttributeStyle
+	// attributeOwners is the owner groups of the attributes of the schemas by schema id, resolved when the
+	// AttributeStyle is ByOwner
+	attributeOwners map[string][]*attributeOwnerGroup
+	// WorkspaceRoot is the path to a kcl.mod-rooted workspace. If set, the docs of all the modules discovered in the
+	// workspace are generated into the directories mirroring the workspace layout, with an index of all the modules,
+	// instead of the docs of PackagePath. The index is only rendered for the Markdown and Html formats, since the
+	// other formats have no page to link the modules from
+	WorkspaceRoot string
+	// MigrationFrom is the path to the previous version of the package. If set, a MIGRATION.md guide with the changes
+	// from the previous version is generated
+	MigrationFrom string
+	// ChangelogSince is the version to collect the changes of. If set, a CHANGELOG.md fragment with the schemas and
+	// attributes introduced at the version declared with the `@since` tags is generated
+	ChangelogSince string
 }
 
 // GenOpts is the user interface defines the doc generate options
@@ -66,14 +242,24 @@ type GenOpts struct {
 	EscapeHtml bool
 	// TemplateDir defines the relative path from the package root to the template directory
 	TemplateDir string
+	// Incremental defines whether to render only the packages whose .k sources changed since the last generation.
+	// The existing docs directory is kept to reuse its manifest and outputs
+	Incremental bool
+	// Logger is the logger of the generation diagnostics. Defaults to printing the info messages to the stdout and
+	// the warning messages to the stderr
+	Logger Logger
 }
 
 type Format string
 
 const (
-	Html     Format = "html"
-	Markdown Format = "md"
-	OpenAPI  Format = "openapi"
+	Html       Format = "html"
+	Markdown   Format = "md"
+	OpenAPI    Format = "openapi"
+	JsonSchema Format = "jsonschema"
+	Jsonnet    Format = "jsonnet"
+	CEL        Format = "cel"
+	Kcl        Format = "kcl"
 )
 
 // KclPackage contains package information of package metadata(such as name, version, description, ...) and exported models(such as schemas)
@@ -87,20 +273,140 @@ type KclPackage struct {
 	SubPackageList    []*KclPackage     `json:"subPackageList,omitempty"` // the sub package list sorted by name in the KCL package
 }
 
+// clone returns a copy of the context without the warnings, whose template functions are bound to the copy
+func (g *GenContext) clone() (*GenContext, error) {
+	c := *g
+	c.Warnings = nil
+	tmpl, err := g.Template.Clone()
+	if err != nil {
+		return nil, err
+	}
+	c.Template = tmpl.Funcs(c.funcMap())
+	return &c, nil
+}
+
+// renderLocales renders the docs for each of the Locales into the directories named by the locales, or renders the
+// docs for the Locale if Locales is empty
+func (g *GenContext) renderLocales(spec *SwaggerV2Spec) error {
+	if len(g.Locales) == 0 {
+		return g.render(spec)
+	}
+	for _, locale := range g.Locales {
+		if !localeTagRegexp.MatchString(locale) {
+			return fmt.Errorf("invalid locale %s", locale)
+		}
+		localized, err := g.clone()
+		if err != nil {
+			return err
+		}
+		localized.Locale = locale
+		localized.Target = filepath.Join(g.Target, locale)
+		if err := localized.render(spec); err != nil {
+			return err
+		}
+		g.Warnings = append(g.Warnings, localized.Warnings...)
+		// the links are the same in all the locales
+		g.UnresolvedLinks = localized.UnresolvedLinks
+	}
+	return nil
+}
+
 func (g *GenContext) render(spec *SwaggerV2Spec) error {
+	if g.TechDocs && g.Format != Markdown {
+		return fmt.Errorf("the TechDocs layout only supports the %s format", Markdown)
+	}
+	if g.EmitPDF && g.Format != Html {
+		return fmt.Errorf("the PDF output only supports the %s format", Html)
+	}
+	if g.TechDocs && g.FilePerSchema {
+		return fmt.Errorf("the TechDocs layout does not support the file per schema layout")
+	}
+	if g.IgnoreDeprecated && parsePackageDeprecation(spec.Info.Description) != nil {
+		g.logger().Info("skipping the deprecated package", "package", spec.Info.Title)
+		return nil
+	}
+	filter, err := g.compileSchemaFilter()
+	if err != nil {
+		return err
+	}
 	// make directory
-	err := os.MkdirAll(g.Target, 0755)
+	if g.memoryOutputs == nil {
+		err = os.MkdirAll(g.Target, 0755)
+		if err != nil {
+			return fmt.Errorf("failed to create docs/ directory under the target directory: %s", err)
+		}
+	}
+	err = g.collectSchemaExamples(spec)
 	if err != nil {
-		return fmt.Errorf("failed to create docs/ directory under the target directory: %s", err)
+		return err
+	}
+	g.collectInheritedAttributes(spec)
+	g.collectAttributeOwners(spec)
+	err = g.checkInheritanceConflicts(spec)
+	if err != nil {
+		return err
+	}
+	g.collectSchemaMetrics(spec)
+	err = g.checkOrphanSchemas(spec)
+	if err != nil {
+		return err
+	}
+	err = g.checkDefaultTypes(spec)
+	if err != nil {
+		return err
+	}
+	if g.ShowEvaluatedExample {
+		g.evaluateSchemaExamples(spec)
+	}
+	g.collectDefaultsShapes(spec)
+	g.collectRelatedSchemas(spec)
+	g.collectImportStatements(spec)
+	g.collectSchemaSummaries(spec)
+	err = g.verifyLinks()
+	if err != nil {
+		return err
 	}
 	// render the package
-	err = g.renderPackage(spec, g.Target)
+	docSpec := spec
+	g.excludedAnchors = nil
+	if filter != nil && (g.Format == Markdown || g.Format == Html) {
+		docSpec, g.excludedAnchors = filter.filterSpec(spec)
+	}
+	err = g.renderPackage(docSpec, g.Target)
 	if err != nil {
 		return err
 	}
+	if g.ChangelogSince != "" {
+		err = g.renderChangelog(docSpec)
+		if err != nil {
+			return err
+		}
+	}
+	if g.IncludeMetrics {
+		err = g.renderMetrics()
+		if err != nil {
+			return err
+		}
+	}
+	if g.EmitPackageReadme {
+		err = g.renderPackageReadmes(docSpec)
+		if err != nil {
+			return err
+		}
+	}
+	if g.EmitPDF {
+		return g.renderPDF(docSpec)
+	}
 	return nil
 }
 
+// warnf records a warning during the generation and prints it
+func (g *GenContext) warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	g.Warnings = append(g.Warnings, msg)
+	g.logger().Warn(msg)
+}
+
 // toKclPackage extracts a kcl package and sub packages, schemas from a SwaggerV2 spec
 func (spec SwaggerV2Spec) toKclPackage() *KclPackage {
 	rootPkg := &KclPackage{
@@ -163,24 +469,71 @@ func addOrCreateSchema(pkg *KclPackage, schemaName string, schema *KclOpenAPITyp
 	}
 }
 
-func funcMap() template.FuncMap {
+func (g *GenContext) funcMap() template.FuncMap {
 	return template.FuncMap{
-		"containsString": func(list []string, elem string) bool {
-			for _, s := range list {
-				if s == elem {
-					return true
-				}
+		"containsString": containsString,
+		"kclType": func(tpe KclOpenAPIType, escapeHtml bool) string {
+			return g.linkDocFiles(&tpe, g.kclTypeName(&tpe, escapeHtml))
+		},
+		"docText": func(doc string) string {
+			return g.localizedDocText(doc)
+		},
+		"cardinality": cardinality,
+		"tableShowExample": func() bool {
+			return g.TableShowExample
+		},
+		"attributeExample": attributeExample,
+		"isUnitLiteral":    isUnitLiteral,
+		"relatedNote": func(schema KclOpenAPIType, name string) string {
+			if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
 			}
-			return false
+			return g.linkDocFiles(nil, g.unlinkExcludedSchemas(g.relatedNotes[schema.schemaId()+"."+name]))
 		},
-		"kclType": func(tpe KclOpenAPIType, escapeHtml bool) string {
-			return tpe.GetKclTypeName(false, true, escapeHtml)
+		"importStatement": func(schema KclOpenAPIType) string {
+			if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
+			}
+			return g.importStatements[schema.schemaId()]
 		},
-		"fullTypeName": func(tpe KclOpenAPIType) string {
-			if tpe.KclExtensions.XKclModelType.Import.Package != "" {
-				return fmt.Sprintf("%s.%s", tpe.KclExtensions.XKclModelType.Import.Package, tpe.KclExtensions.XKclModelType.Type)
+		"withTypeExpression": func(tpe KclOpenAPIType, label string) string {
+			return g.withTypeExpression(&tpe, label)
+		},
+		"constraintExamples": func(schema KclOpenAPIType, name string) string {
+			if !g.ShowConstraintExamples {
+				return ""
+			}
+			return constraintExamples(&schema, name)
+		},
+		"formatHint": func(tpe KclOpenAPIType) string {
+			return formatHint(&tpe)
+		},
+		"elementSummary": func(tpe KclOpenAPIType) string {
+			return g.elementSummary(&tpe)
+		},
+		"defaultsShape": func(schema KclOpenAPIType) string {
+			if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
+			}
+			return g.defaultsShapes[schema.schemaId()]
+		},
+		"schemaMetrics": func(schema KclOpenAPIType) string {
+			if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
+			}
+			if m, ok := g.schemaMetrics[schema.schemaId()]; ok {
+				return m.metricsLine()
 			}
-			return tpe.KclExtensions.XKclModelType.Type
+			return ""
+		},
+		"attributeGroups": func(schema KclOpenAPIType) []string {
+			return attributeGroups(&schema)
+		},
+		"attributePresence": func(schema KclOpenAPIType, name string, property KclOpenAPIType) string {
+			return attributePresence(&schema, name, &property)
+		},
+		"fullTypeName": func(tpe KclOpenAPIType) string {
+			return tpe.schemaId()
 		},
 		"escapeHtml": func(original string, escapeHtml bool) string {
 			// escape html symbols if needed
@@ -192,17 +545,269 @@ func funcMap() template.FuncMap {
 			original = strings.Replace(original, "&#34;", "\"", -1)
 			return original
 		},
+		"attributeAnchor": func(schema KclOpenAPIType, name string) string {
+			if !g.attributeAnchorsEnabled() {
+				return ""
+			}
+			return fmt.Sprintf(`<a id="%s"></a>`, attributeAnchorId(&schema, name))
+		},
+		"requiredQuickRef": func(schema KclOpenAPIType) string {
+			if !g.ShowRequiredQuickRef || schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
+			}
+			return g.requiredQuickRef(&schema)
+		},
+		"autolinkAttributes": func(description string, schema KclOpenAPIType) string {
+			if !g.AutolinkAttributes {
+				return description
+			}
+			return autolinkAttributes(description, &schema)
+		},
+		"isInheritedAttribute": func(schema KclOpenAPIType, name string) bool {
+			group := g.inheritedAttributeGroup(&schema)
+			return group != nil && containsString(group.Names, name)
+		},
+		"inheritedAttributes": func(schema KclOpenAPIType) *inheritedAttributeGroup {
+			return g.inheritedAttributeGroup(&schema)
+		},
+		"attributeOwnerGroups": func(schema KclOpenAPIType) []*attributeOwnerGroup {
+			return g.attributeOwnerGroupsOf(&schema)
+		},
 		"arr": func(els ...any) []any {
 			return els
 		},
 		"sourcePath": func(tpe KclOpenAPIType) string {
-			// todo: let users specify the source code base path
-			return filepath.Join(tpe.GetSchemaPkgDir(""), tpe.KclExtensions.XKclModelType.Import.Alias)
+			return g.resolveSourcePath(sourceFilePath(tpe.GetSchemaPkgDir(""), tpe.KclExtensions.XKclModelType.Import.Alias))
+		},
+		"showSourceFiles": func() bool {
+			return g.ShowSourceFiles
+		},
+		"sourceSectionTitle": g.sourceSectionTitle,
+		"sourceFiles": func(tpe KclOpenAPIType) []string {
+			return g.schemaSourceFiles(&tpe)
 		},
 		"indexContent": func(pkg *KclPackage) string {
-			return pkg.getIndexContent(0, "  ")
+			if g.FilePerSchema {
+				return g.fileIndexContent(pkg)
+			}
+			return indexContent(pkg)
+		},
+		"filePerSchema": func() bool {
+			return g.FilePerSchema
 		},
+		"packageDeprecationBanner": g.packageDeprecationBanner,
+		"schemaKind": func(schema KclOpenAPIType) string {
+			if kind := schema.kind(); kind != schemaKind {
+				return kind
+			}
+			return ""
+		},
+		"protocolSignature": func(schema KclOpenAPIType) string {
+			if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+				return ""
+			}
+			return protocolSignature(&schema)
+		},
+	}
+}
+
+// kclTypeName renders the type of the attribute with the links to the referenced schemas
+func (g *GenContext) kclTypeName(tpe *KclOpenAPIType, escapeHtml bool) string {
+	if deprecated := tpe.deprecatedEnumValues(); len(deprecated) > 0 {
+		return g.unlinkExcludedSchemas(g.enumTypeName(tpe, deprecated, escapeHtml))
+	}
+	if tpe.isAnyType() {
+		return anyTypeLabel
+	}
+	if label, ok := untypedContainerLabel(tpe, escapeHtml); ok {
+		return g.unlinkExcludedSchemas(label)
 	}
+	return g.unlinkExcludedSchemas(tpe.GetKclTypeName(false, true, escapeHtml))
+}
+
+func (g *GenContext) attributeAnchorsEnabled() bool {
+	return g.AttributeAnchors || g.AutolinkAttributes || g.ShowRequiredQuickRef
+}
+
+// attributeAnchorId returns the anchor id of the schema attribute qualified by the package of the schema, such as
+// `person-name` in the root package and `models-person-name` in the models package, so the schemas of the same name
+// in different packages have different anchors
+func attributeAnchorId(schema *KclOpenAPIType, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s", strings.ReplaceAll(schema.schemaId(), ".", "-"), name))
+}
+
+var inlineCodeRegexp = regexp.MustCompile("`([^`]+)`")
+
+// autolinkAttributes links the inline code which exactly matches an attribute name of the schema to the attribute anchor
+func autolinkAttributes(description string, schema *KclOpenAPIType) string {
+	return inlineCodeRegexp.ReplaceAllStringFunc(description, func(code string) string {
+		name := strings.Trim(code, "`")
+		if _, ok := schema.Properties[name]; !ok {
+			return code
+		}
+		return fmt.Sprintf("[%s](#%s)", code, attributeAnchorId(schema, name))
+	})
+}
+
+// cardinality returns the cardinality of the list or dict attribute such as `1..* items` and `0..10 entries`,
+// and an empty string for other types
+func cardinality(tpe KclOpenAPIType) string {
+	var min, max *int
+	var unit string
+	switch {
+	case tpe.Type == Array:
+		min, max, unit = tpe.MinItems, tpe.MaxItems, "items"
+	case tpe.Type == Object && tpe.AdditionalProperties != nil:
+		min, max, unit = tpe.MinProperties, tpe.MaxProperties, "entries"
+	default:
+		return ""
+	}
+	lower, upper := "0", "*"
+	if min != nil {
+		lower = strconv.Itoa(*min)
+	}
+	if max != nil {
+		upper = strconv.Itoa(*max)
+	}
+	return fmt.Sprintf("%s..%s %s", lower, upper, unit)
+}
+
+// anyTypeLabel is the type label of the `any` attributes, which the values of any type are accepted without checking
+const anyTypeLabel = "`any` (unconstrained)<br />No type checking applies."
+
+// untypedContainerLabel returns the type label of the list whose items are untyped, such as `[any]` for `[]`, and of
+// the dict whose values are untyped, such as `{str:any}` for `{str:}`, with the note that the elements are not checked
+func untypedContainerLabel(tpe *KclOpenAPIType, escapeHtml bool) (string, bool) {
+	switch {
+	case tpe.Type == Array && tpe.Items != nil && tpe.Items.isAnyType():
+		return "[any]<br />No type checking applies to the items.", true
+	case tpe.Type == Object && tpe.AdditionalProperties != nil && tpe.AdditionalProperties.isAnyType():
+		key := typAny
+		if tpe.KclExtensions != nil && tpe.KclExtensions.XKclDictKeyType != nil && !tpe.KclExtensions.XKclDictKeyType.isAnyType() {
+			key = tpe.KclExtensions.XKclDictKeyType.GetKclTypeName(false, true, escapeHtml)
+		}
+		return fmt.Sprintf("{%s:any}<br />No type checking applies to the values.", key), true
+	}
+	return "", false
+}
+
+// attributeExample returns a sample value of the attribute for the example column: the default value if present,
+// else a placeholder of the type, or the name of the referenced schema
+func attributeExample(tpe KclOpenAPIType) string {
+	if tpe.Default != "" {
+		return tpe.Default
+	}
+	if len(tpe.Enum) > 0 {
+		return tpe.Enum[0]
+	}
+	if tpe.Ref != "" {
+		return schemaShortName(Ref2SchemaId(tpe.Ref))
+	}
+	switch tpe.Type {
+	case String:
+		return `"string"`
+	case Integer:
+		if tpe.Format == NumberMultiplier {
+			return "1Ki"
+		}
+		return "1"
+	case Number:
+		return "1.0"
+	case Bool:
+		return "True"
+	case Array:
+		if tpe.Items == nil {
+			return "[]"
+		}
+		if item := attributeExample(*tpe.Items); item != "" {
+			return fmt.Sprintf("[%s]", item)
+		}
+		return "[]"
+	case Object:
+		if tpe.AdditionalProperties != nil {
+			if value := attributeExample(*tpe.AdditionalProperties); value != "" {
+				return fmt.Sprintf(`{"key": %s}`, value)
+			}
+			return "{}"
+		}
+		if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+			return attributeExample(*tpe.KclExtensions.XKclUnionTypes[0])
+		}
+	}
+	// no sample value of the any type
+	return ""
+}
+
+// attributeGroupTitles is the callout titles of the attribute group kinds
+var attributeGroupTitles = map[AttributeGroupKind]string{
+	ExactlyOne: "Exactly one of",
+	AtMostOne:  "At most one of",
+	AtLeastOne: "At least one of",
+}
+
+// attributeGroups returns the callouts of the attribute groups of the schema, such as "Exactly one of: `a`, `b`"
+func attributeGroups(schema *KclOpenAPIType) []string {
+	if schema.KclExtensions == nil {
+		return nil
+	}
+	var callouts []string
+	for _, group := range schema.KclExtensions.XKclAttributeGroups {
+		names := make([]string, len(group.Attributes))
+		for i, name := range group.Attributes {
+			names[i] = fmt.Sprintf("`%s`", name)
+		}
+		callouts = append(callouts, fmt.Sprintf("%s: %s", attributeGroupTitles[group.Kind], strings.Join(names, ", ")))
+	}
+	return callouts
+}
+
+// attributePresence describes whether the attribute may be omitted (`name?: T`) and whether its value may be None
+// (`name: T | None`), and returns an empty string for the required attribute which does not accept None
+func attributePresence(schema *KclOpenAPIType, name string, property *KclOpenAPIType) string {
+	optional := !containsString(schema.Required, name)
+	switch {
+	case optional && property.Nullable:
+		return "Optional (may be omitted), nullable (may be `None`)"
+	case optional:
+		return "Optional (may be omitted)"
+	case property.Nullable:
+		return "Nullable (may be `None`)"
+	}
+	return ""
+}
+
+// enumTypeName renders the values of an enum type. The deprecated values are hidden if IgnoreDeprecated is set, otherwise they are struck through with the deprecation note
+func (g *GenContext) enumTypeName(tpe *KclOpenAPIType, deprecated map[string]string, escapeHtml bool) string {
+	values := []*KclOpenAPIType{tpe}
+	if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+		values = tpe.KclExtensions.XKclUnionTypes
+	}
+	var names []string
+	for _, v := range values {
+		name := v.GetKclTypeName(true, true, escapeHtml)
+		note, ok := deprecated[name]
+		if !ok && v.ReadOnly {
+			note, ok = deprecated[strings.Trim(name, `"`)]
+		}
+		if !ok || !v.ReadOnly {
+			names = append(names, name)
+			continue
+		}
+		if g.IgnoreDeprecated {
+			g.logger().Debug("skipping the deprecated enum value", "value", name)
+			continue
+		}
+		note = strings.Replace(note, "|", "\\|", -1)
+		if note == "" {
+			names = append(names, fmt.Sprintf("~~%s~~ (deprecated)", name))
+		} else {
+			names = append(names, fmt.Sprintf("~~%s~~ (deprecated: %s)", name, note))
+		}
+	}
+	if escapeHtml {
+		return strings.Join(names, htmlTmpl.HTMLEscapeString(" \\| "))
+	}
+	return strings.Join(names, " | ")
 }
 
 func (pkg *KclPackage) getPackageIndexContent(level int, indentation string) string {
@@ -239,10 +844,13 @@ func (g *GenContext) renderPackage(spec *SwaggerV2Spec, parentDir string) error
 	if pkg.Name == "" {
 		pkgName = "main"
 	}
-	fmt.Printf("generating doc for package %s\n", pkgName)
+	g.logger().Info("generating doc for package", "package", pkgName, "format", g.Format)
 	// --- format ---
 	switch strings.ToLower(string(g.Format)) {
 	case string(Markdown):
+		if g.FilePerSchema {
+			return g.renderSchemaFiles(spec, pkg, parentDir)
+		}
 		docFileName := fmt.Sprintf("%s.%s", pkgName, g.Format)
 		var buf bytes.Buffer
 		err := g.Template.ExecuteTemplate(&buf, "packageDoc", struct {
@@ -255,12 +863,27 @@ func (g *GenContext) renderPackage(spec *SwaggerV2Spec, parentDir string) error
 		if err != nil {
 			return fmt.Errorf("failed to render package %s with template, err: %s", pkg.Name, err)
 		}
+		content := g.withToolHeader(docFileName, buf.Bytes())
+		if g.TechDocs {
+			docFileName = techDocsIndexFile
+			frontMatter, err := techDocsFrontMatter(pkg, pkgName)
+			if err != nil {
+				return err
+			}
+			content = append([]byte(frontMatter), content...)
+			if err := g.renderTechDocsConfig(pkgName); err != nil {
+				return err
+			}
+		}
 		// write content to file
-		err = os.WriteFile(filepath.Join(parentDir, docFileName), buf.Bytes(), 0644)
+		err = g.writeOutput(parentDir, docFileName, content)
 		if err != nil {
-			return fmt.Errorf("failed to write file %s in %s: %v", docFileName, parentDir, err)
+			return err
 		}
 	case string(Html):
+		if g.FilePerSchema {
+			return g.renderSchemaFiles(spec, pkg, parentDir)
+		}
 		var mdBuf bytes.Buffer
 		err := g.Template.ExecuteTemplate(&mdBuf, "packageDoc", struct {
 			EscapeHtml bool
@@ -272,36 +895,110 @@ func (g *GenContext) renderPackage(spec *SwaggerV2Spec, parentDir string) error
 		if err != nil {
 			return fmt.Errorf("failed to render package %s with template, err: %s", pkg.Name, err)
 		}
-		var htmlBuf bytes.Buffer
-		if err := goldmark.Convert(mdBuf.Bytes(), &htmlBuf); err != nil {
-			panic(err)
+		content := g.markdownToHtml(mdBuf.Bytes())
+		if g.EnableFacets {
+			content = addFacets(content, spec)
 		}
 		docFileName := fmt.Sprintf("%s.%s", pkgName, g.Format)
+		content = g.withToolHeader(docFileName, content)
 		// write content to file
-		err = os.WriteFile(filepath.Join(parentDir, docFileName), htmlBuf.Bytes(), 0644)
+		err = g.writeOutput(parentDir, docFileName, content)
 		if err != nil {
-			return fmt.Errorf("failed to write file %s in %s: %v", docFileName, parentDir, err)
+			return err
 		}
 	case string(OpenAPI):
 		docFileName := fmt.Sprintf("%s.%s", pkgName, "json")
 		spec := SwaggerV2ToOpenAPIV3Spec(spec)
+		if g.EmitOperationStubs {
+			AddOperationStubs(spec, g.OperationPathTemplate)
+		}
 		json, err := spec.MarshalJSON()
 		if err != nil {
 			return err
 		}
 		// write content to file
-		err = os.WriteFile(filepath.Join(parentDir, docFileName), json, 0644)
+		err = g.writeOutput(parentDir, docFileName, json)
+		if err != nil {
+			return err
+		}
+	case string(JsonSchema):
+		files := map[string][]byte{}
+		if g.JsonSchemaFilePerSchema {
+			var err error
+			files, err = ExportJsonSchemaFiles(spec)
+			if err != nil {
+				return err
+			}
+		} else {
+			content, err := json.MarshalIndent(ExportJsonSchema(spec), "", "    ")
+			if err != nil {
+				return err
+			}
+			files[fmt.Sprintf("%s.schema.json", pkgName)] = content
+		}
+		for docFileName, content := range files {
+			// write content to file
+			err := g.writeOutput(parentDir, docFileName, content)
+			if err != nil {
+				return err
+			}
+		}
+	case string(Jsonnet):
+		docFileName := fmt.Sprintf("%s.libsonnet", pkgName)
+		// write content to file
+		err := g.writeOutput(parentDir, docFileName, g.withToolHeader(docFileName, ExportJsonnetLibrary(spec)))
 		if err != nil {
-			return fmt.Errorf("failed to write file %s in %s: %v", docFileName, parentDir, err)
+			return err
+		}
+	case string(CEL):
+		files, skipped := ExportCELValidations(spec)
+		for _, s := range skipped {
+			g.warnf("skipped the check expression which can not be translated to CEL: %s", s)
+		}
+		for docFileName, content := range files {
+			// write content to file
+			err := g.writeOutput(parentDir, docFileName, g.withToolHeader(docFileName, content))
+			if err != nil {
+				return err
+			}
+		}
+	case string(Kcl):
+		files := ExportKclSchemas(spec)
+		for _, path := range getSortedKeys(files) {
+			// write content to file, the files of the sub packages are in the package directories
+			docFileName := filepath.Base(path)
+			err := g.writeOutput(filepath.Join(parentDir, filepath.Dir(path)), docFileName, g.withToolHeader(docFileName, files[path]))
+			if err != nil {
+				return err
+			}
 		}
 	default:
-		return fmt.Errorf("invalid generate format. Allow values: %s", []Format{Markdown, Html, OpenAPI})
+		return fmt.Errorf("invalid generate format. Allow values: %s", []Format{Markdown, Html, OpenAPI, JsonSchema, Jsonnet, CEL, Kcl})
 	}
 	return nil
 }
 
+// markdownToHtml converts the markdown doc to html with the type expression titles and the copy buttons. The
+// <details> elements of the collapsed inherited attributes are kept
+func (g *GenContext) markdownToHtml(content []byte) []byte {
+	var htmlBuf bytes.Buffer
+	md := goldmark.New()
+	if g.CollapseInherited || g.attributeAnchorsEnabled() {
+		// keep the <details> elements of the collapsed inherited attributes and the <a> anchors of the attributes
+		md = goldmark.New(goldmark.WithRendererOptions(html.WithUnsafe()))
+	}
+	if err := md.Convert(content, &htmlBuf); err != nil {
+		panic(err)
+	}
+	content = addTypeExpressionTitles(htmlBuf.Bytes())
+	if g.EnableCopyButtons {
+		content = addCopyButtons(content)
+	}
+	return content
+}
+
 func (opts *GenOpts) ValidateComplete() (*GenContext, error) {
-	g := &GenContext{}
+	g := &GenContext{Logger: opts.Logger, Incremental: opts.Incremental}
 	// --- format ---
 	switch strings.ToLower(opts.Format) {
 	case string(Markdown):
@@ -310,8 +1007,16 @@ func (opts *GenOpts) ValidateComplete() (*GenContext, error) {
 		g.Format = Html
 	case string(OpenAPI):
 		g.Format = OpenAPI
+	case string(JsonSchema):
+		g.Format = JsonSchema
+	case string(Jsonnet):
+		g.Format = Jsonnet
+	case string(CEL):
+		g.Format = CEL
+	case string(Kcl):
+		g.Format = Kcl
 	default:
-		return nil, fmt.Errorf("invalid generate format. Allow values: %s", []Format{Markdown, Html, OpenAPI})
+		return nil, fmt.Errorf("invalid generate format. Allow values: %s", []Format{Markdown, Html, OpenAPI, JsonSchema, Jsonnet, CEL, Kcl})
 	}
 
 	// --- package path ---
@@ -381,7 +1086,7 @@ func (opts *GenOpts) ValidateComplete() (*GenContext, error) {
 		}
 	}
 	// parse template
-	g.Template = template.New("").Funcs(funcMap())
+	g.Template = template.New("").Funcs(g.funcMap())
 	_, err = g.Template.Parse(g.SchemaDocTmpl)
 	if err != nil {
 		return nil, err
@@ -415,9 +1120,9 @@ func (opts *GenOpts) ValidateComplete() (*GenContext, error) {
 		g.Target = opts.Target
 	}
 	g.Target = path.Join(g.Target, "docs")
-	if _, err := os.Stat(g.Target); err == nil {
+	if _, err := os.Stat(g.Target); err == nil && !g.Incremental {
 		// check and warn if the docs directory already exists
-		fmt.Printf("[Warn] path %s exists, all the content will be overwritten\n", g.Target)
+		g.logger().Warn("the target path exists, all the content will be overwritten", "path", g.Target)
 		if err := os.RemoveAll(g.Target); err != nil {
 			return nil, fmt.Errorf("failed to remove existing content in %s:%s", g.Target, err)
 		}
@@ -428,13 +1133,34 @@ func (opts *GenOpts) ValidateComplete() (*GenContext, error) {
 
 // GenDoc generate document files from KCL source files
 func (g *GenContext) GenDoc() error {
-	spec, err := ExportSwaggerV2Spec(g.PackagePath)
+	if _, err := g.compileSchemaFilter(); err != nil {
+		return err
+	}
+	if g.WorkspaceRoot != "" {
+		if g.MigrationFrom != "" {
+			return fmt.Errorf("the migration guide is not supported when generating docs for a workspace")
+		}
+		return g.genWorkspaceDoc()
+	}
+	if g.Incremental && g.MigrationFrom == "" {
+		return g.genIncrementalDoc()
+	}
+	spec, err := exportSwaggerV2Spec(g.PackagePath, g.logger())
 	if err != nil {
 		return err
 	}
-	err = g.render(spec)
+	err = g.renderLocales(spec)
 	if err != nil {
 		return fmt.Errorf("render doc failed: %s", err)
 	}
+	if g.MigrationFrom != "" {
+		oldSpec, err := exportSwaggerV2Spec(g.MigrationFrom, g.logger())
+		if err != nil {
+			return fmt.Errorf("failed to export the previous version from %s: %s", g.MigrationFrom, err)
+		}
+		if err := g.renderMigrationGuide(oldSpec, spec); err != nil {
+			return fmt.Errorf("render migration guide failed: %s", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,211 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// annotationRegexp matches a docstring line like `@name value`
+var annotationRegexp = regexp.MustCompile(`^@([a-zA-Z][a-zA-Z0-9_-]*)(?:\s+(.*))?$`)
+
+// blockAnnotations are the annotations whose value can span the following lines
+var blockAnnotations = map[string]bool{
+	"example": true,
+}
+
+// localeTagRegexp matches the valid locales such as `zh` and `pt-BR`
+var localeTagRegexp = regexp.MustCompile(`^[a-z]{2}(-[A-Za-z]{2,4})?$`)
+
+// isBlockAnnotation checks if the value of the annotation can span the following lines, the locale tags are block
+// annotations only for the given locales, so the other short tags such as `@id` are not taken as translations
+func isBlockAnnotation(name string, locales []string) bool {
+	return blockAnnotations[name] || containsString(locales, name)
+}
+
+// docAnnotation is a `@name value` tag line in a schema or attribute docstring
+type docAnnotation struct {
+	Name  string
+	Value string
+}
+
+// parseDocAnnotations splits a docstring into the plain description text and the annotation tags in it.
+// The value of a block annotation such as `@example` or a tag of the locales without an inline value is the following
+// lines until an empty line.
+func parseDocAnnotations(doc string, locales ...string) (string, []docAnnotation) {
+	var lines []string
+	var annotations []docAnnotation
+	var block *docAnnotation
+	for _, line := range strings.Split(doc, "\n") {
+		if block != nil {
+			if strings.TrimSpace(line) != "" {
+				block.Value += line + "\n"
+				continue
+			}
+			block.Value = strings.TrimRight(block.Value, "\n")
+			annotations = append(annotations, *block)
+			block = nil
+		}
+		if m := annotationRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			a := docAnnotation{
+				Name:  m[1],
+				Value: strings.TrimSpace(m[2]),
+			}
+			if a.Value == "" && isBlockAnnotation(a.Name, locales) {
+				block = &a
+				continue
+			}
+			annotations = append(annotations, a)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if block != nil {
+		block.Value = strings.TrimRight(block.Value, "\n")
+		annotations = append(annotations, *block)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), annotations
+}
+
+// docText returns the docstring without the annotation tags and the translations of the locales
+func docText(doc string, locales ...string) string {
+	text, annotations := parseDocAnnotations(doc, locales...)
+	if len(annotations) == 0 {
+		return doc
+	}
+	return text
+}
+
+// localizedDocText returns the translation of the docstring in the `@<locale>` tag of the Locale, and falls back to
+// the docstring without the annotation tags. The tags of the Locale and the Locales are taken as the translations.
+func (g *GenContext) localizedDocText(doc string) string {
+	locales := g.localeTags()
+	if g.Locale != "" {
+		_, annotations := parseDocAnnotations(doc, locales...)
+		for _, a := range annotations {
+			if a.Name == g.Locale && a.Value != "" {
+				return a.Value
+			}
+		}
+	}
+	return docText(doc, locales...)
+}
+
+// localeTags returns the configured locales whose tags are the translations of the docstrings
+func (g *GenContext) localeTags() []string {
+	if g.Locale != "" && !containsString(g.Locales, g.Locale) {
+		return append([]string{g.Locale}, g.Locales...)
+	}
+	return g.Locales
+}
+
+// annotationValues returns the values of the annotation tags with the name in the docstring
+func annotationValues(doc string, name string) []string {
+	_, annotations := parseDocAnnotations(doc)
+	var values []string
+	for _, a := range annotations {
+		if a.Name == name && a.Value != "" {
+			values = append(values, a.Value)
+		}
+	}
+	return values
+}
+
+// isInternal checks if the attribute is internal, which is tagged with `@internal` in its docstring or named with
+// the `_` prefix
+func (tpe *KclOpenAPIType) isInternal(name string) bool {
+	if strings.HasPrefix(name, "_") {
+		return true
+	}
+	_, annotations := parseDocAnnotations(tpe.Description)
+	for _, a := range annotations {
+		if a.Name == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedEnumValues returns the deprecated enum values declared with `@deprecated <value> [note]` tags and their
+// notes, the tags whose value is not a literal in the union type of the attribute are not enum deprecations
+func (tpe *KclOpenAPIType) deprecatedEnumValues() map[string]string {
+	literals := tpe.literalNames()
+	if len(literals) == 0 {
+		return nil
+	}
+	_, annotations := parseDocAnnotations(tpe.Description)
+	deprecated := map[string]string{}
+	for _, a := range annotations {
+		if a.Name != "deprecated" || a.Value == "" {
+			continue
+		}
+		value, note := splitEnumValue(a.Value)
+		if literals[value] {
+			deprecated[value] = note
+		}
+	}
+	return deprecated
+}
+
+// literalNames returns the literal values in the type or its union types, the string literals are included both
+// quoted and unquoted
+func (tpe *KclOpenAPIType) literalNames() map[string]bool {
+	values := []*KclOpenAPIType{tpe}
+	if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+		values = tpe.KclExtensions.XKclUnionTypes
+	}
+	literals := map[string]bool{}
+	for _, v := range values {
+		if !v.ReadOnly {
+			continue
+		}
+		name := v.GetKclTypeName(true, true, false)
+		literals[name] = true
+		literals[strings.Trim(name, `"`)] = true
+	}
+	return literals
+}
+
+// splitEnumValue splits the leading enum literal(quoted string or a bare token) from the rest of the text
+func splitEnumValue(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if end := strings.Index(s[1:], `"`); end >= 0 {
+			return s[:end+2], trimNote(s[end+2:])
+		}
+	}
+	if i := strings.IndexAny(s, " \t:"); i >= 0 {
+		return s[:i], trimNote(s[i:])
+	}
+	return s, ""
+}
+
+func trimNote(s string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), ":"))
+}
+
+// isDeprecated checks if the schema or attribute is decorated with `@deprecated`
+func (tpe *KclOpenAPIType) isDeprecated() bool {
+	if tpe.KclExtensions == nil {
+		return false
+	}
+	for _, d := range tpe.KclExtensions.XKclDecorators {
+		if d.Name == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultStability is the stability of the schemas without the `@stability` tag
+const defaultStability = "stable"
+
+// stability returns the stability of the schema declared with the `@stability <level>` tag such as `experimental`,
+// and `deprecated` for the schema decorated with `@deprecated`
+func (tpe *KclOpenAPIType) stability() string {
+	if tpe.isDeprecated() {
+		return "deprecated"
+	}
+	if values := annotationValues(tpe.Description, "stability"); len(values) > 0 {
+		return strings.ToLower(values[0])
+	}
+	return defaultStability
+}
@@ -0,0 +1,265 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// docCacheFile is the manifest of the incremental generation written in the target directory
+	docCacheFile    = ".kcl-doc-cache.json"
+	docCacheVersion = 1
+)
+
+// docCache is the manifest of the incremental generation, which records the content hash of the sources and the
+// rendered outputs of each package
+type docCache struct {
+	Version  int                         `json:"version"`
+	Format   Format                      `json:"format"`
+	Options  string                      `json:"options"`  // hash of the generation options and templates
+	Packages map[string]*docCachePackage `json:"packages"` // by the slash-separated package path relative to the root
+}
+
+// docCachePackage is the manifest entry of a package
+type docCachePackage struct {
+	Hash        string   `json:"hash"`    // content hash of the .k files and kcl.mod of the package
+	Outputs     []string `json:"outputs"` // slash-separated paths of the rendered files relative to the target
+	Name        string   `json:"name,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// Deprecation is the deprecation of the package declared in the package docstring
+	Deprecation *packageDeprecation `json:"deprecation,omitempty"`
+}
+
+// cachedSpec is the exported spec of a package and the content hash of the package sources it is exported from
+type cachedSpec struct {
+	hash string
+	spec *SwaggerV2Spec
+}
+
+// specCache is the in-memory cache of the latest exported spec of each package by the package path, so the
+// unchanged packages are not parsed again in the same process. A changed package replaces its entry, so the cache
+// does not grow with the generations
+var specCache = struct {
+	sync.Mutex
+	specs map[string]cachedSpec
+}{specs: map[string]cachedSpec{}}
+
+// exportCachedSpec exports the spec of the package, or returns the cached one if the sources are unchanged
+func (g *GenContext) exportCachedSpec(pkgPath string, hash string) (*SwaggerV2Spec, error) {
+	specCache.Lock()
+	defer specCache.Unlock()
+	if cached, ok := specCache.specs[pkgPath]; ok && cached.hash == hash {
+		g.logger().Debug("reusing the cached spec of the unchanged package", "package", pkgPath)
+		return cached.spec, nil
+	}
+	spec, err := exportSwaggerV2Spec(pkgPath, g.logger())
+	if err != nil {
+		return nil, err
+	}
+	specCache.specs[pkgPath] = cachedSpec{hash: hash, spec: spec}
+	return spec, nil
+}
+
+// docCacheOptions returns the hash of the options and the templates which affect the rendered docs. The paths, the
+// incremental mode options, the logger and the collected diagnostics are not hashed, and the SourcePathResolver
+// function is only hashed by whether it is set
+func (g *GenContext) docCacheOptions() (string, error) {
+	opts := *g
+	opts.PackagePath, opts.Target, opts.WorkspaceRoot = "", "", ""
+	opts.Incremental, opts.ForceFull = false, false
+	opts.Template, opts.Logger = nil, nil
+	opts.Warnings, opts.UnresolvedLinks = nil, nil
+	content, err := json.Marshal(struct {
+		Options            *GenContext
+		SourcePathResolver bool
+	}{&opts, g.SourcePathResolver != nil})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash the doc options: %v", err)
+	}
+	h := sha256.Sum256(content)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// readDocCache reads the manifest in the target directory. An empty manifest is returned if it does not exist, is
+// invalid, or is written for another format or other options, so all the packages are rendered again
+func readDocCache(target string, format Format, options string) *docCache {
+	empty := &docCache{Version: docCacheVersion, Format: format, Options: options, Packages: map[string]*docCachePackage{}}
+	content, err := os.ReadFile(filepath.Join(target, docCacheFile))
+	if err != nil {
+		return empty
+	}
+	cache := &docCache{}
+	if err := json.Unmarshal(content, cache); err != nil || cache.Version != docCacheVersion || cache.Format != format || cache.Options != options || cache.Packages == nil {
+		return empty
+	}
+	return cache
+}
+
+// write writes the manifest in the target directory
+func (c *docCache) write(target string) error {
+	content, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(target, docCacheFile), content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s in %s: %v", docCacheFile, target, err)
+	}
+	return nil
+}
+
+// upToDate returns whether the package is rendered from the sources of the same hash and all its outputs exist
+func (c *docCache) upToDate(target string, rel string, hash string) bool {
+	p, ok := c.Packages[rel]
+	if !ok || p.Hash != hash || len(p.Outputs) == 0 {
+		return false
+	}
+	for _, output := range p.Outputs {
+		if _, err := os.Stat(filepath.Join(target, filepath.FromSlash(output))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// genIncrementalDoc generates the doc of the package unless its sources are unchanged since the last generation
+func (g *GenContext) genIncrementalDoc() error {
+	options, err := g.docCacheOptions()
+	if err != nil {
+		return err
+	}
+	cache := readDocCache(g.Target, g.Format, options)
+	rendered, err := cache.refresh(g, g.Target, ".", g.PackagePath, nil, func(p *docCachePackage) error {
+		spec, err := g.exportCachedSpec(g.PackagePath, p.Hash)
+		if err != nil {
+			return err
+		}
+		if err := g.renderLocales(spec); err != nil {
+			return fmt.Errorf("render doc failed: %s", err)
+		}
+		return nil
+	})
+	if err != nil || !rendered {
+		return err
+	}
+	return cache.write(g.Target)
+}
+
+// refresh renders the package with the render function unless it is up to date, and records its hash and outputs
+// in the manifest. The outputs are the files under the package target directory except the ones under the skipped
+// directories, which are the targets of the nested packages. ForceFull renders the package regardless of the
+// manifest. It returns whether the package is rendered.
+func (c *docCache) refresh(g *GenContext, target string, rel string, pkgPath string, skip []string, render func(p *docCachePackage) error) (bool, error) {
+	hash, err := packageSourceHash(pkgPath, skip)
+	if err != nil {
+		return false, err
+	}
+	if !g.ForceFull && c.upToDate(target, rel, hash) {
+		g.logger().Info("skipping the unchanged package", "package", pkgPath)
+		return false, nil
+	}
+	p := &docCachePackage{Hash: hash}
+	if err := render(p); err != nil {
+		return false, err
+	}
+	p.Outputs, err = listOutputs(target, rel, skip)
+	if err != nil {
+		return false, err
+	}
+	c.Packages[rel] = p
+	return true, nil
+}
+
+// packageSourceHash returns the content hash of the .k files and kcl.mod in the package directory. The hidden
+// directories and the skipped directories relative to the package are not hashed
+func packageSourceHash(pkgPath string, skip []string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(pkgPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(pkgPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || skippedDir(rel, skip)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".k") || d.Name() == kclModFile {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, f := range files {
+		content, err := os.ReadFile(filepath.Join(pkgPath, filepath.FromSlash(f)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", f, len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// listOutputs lists the files under the package target directory relative to the target, except the manifest and
+// the files under the skipped directories relative to the package
+func listOutputs(target string, rel string, skip []string) ([]string, error) {
+	var outputs []string
+	dir := filepath.Join(target, filepath.FromSlash(rel))
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		pkgRel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		pkgRel = filepath.ToSlash(pkgRel)
+		if d.IsDir() {
+			if pkgRel != "." && skippedDir(pkgRel, skip) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != docCacheFile {
+			outputs = append(outputs, path.Join(rel, pkgRel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(outputs)
+	return outputs, nil
+}
+
+func skippedDir(rel string, skip []string) bool {
+	for _, s := range skip {
+		if rel == s {
+			return true
+		}
+	}
+	return false
+}
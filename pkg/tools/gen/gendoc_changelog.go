@@ -0,0 +1,75 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const changelogDocFile = "CHANGELOG.md"
+
+// sinceVersion returns the version declared with the `@since <version>` tag in the docstring
+func sinceVersion(doc string) string {
+	if values := annotationValues(doc, "since"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// sameVersion checks if the versions are the same regardless of the `v` prefix, such as `v1.2.0` and `1.2.0`
+func sameVersion(a, b string) bool {
+	return a != "" && strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// MarkdownChangelogSince renders the changelog fragment of the schemas and attributes introduced at the version
+// declared with the `@since` tags, grouped by package. The attributes of the schemas introduced at the version are
+// not listed again.
+func MarkdownChangelogSince(spec *SwaggerV2Spec, version string) string {
+	changes := map[string][]string{}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		pkg := schema.KclExtensions.XKclModelType.Import.Package
+		if pkg == "" {
+			pkg = "main"
+		}
+		name := schema.KclExtensions.XKclModelType.Type
+		if sameVersion(sinceVersion(schema.Description), version) {
+			change := fmt.Sprintf("- Added the schema `%s`.", name)
+			if summary := firstSentence(docText(schema.Description)); summary != "" {
+				change += " " + summary
+			}
+			changes[pkg] = append(changes[pkg], change)
+			continue
+		}
+		for _, attr := range getSortedKeys(schema.Properties) {
+			if sameVersion(sinceVersion(schema.Properties[attr].Description), version) {
+				changes[pkg] = append(changes[pkg], fmt.Sprintf("- Added the attribute `%s.%s`.", name, attr))
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Changes in %s\n\n", version)
+	if len(changes) == 0 {
+		fmt.Fprintf(&buf, "No schemas or attributes are introduced in %s.\n\n", version)
+	}
+	pkgs := make([]string, 0, len(changes))
+	for pkg := range changes {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		fmt.Fprintf(&buf, "## %s\n\n%s\n\n", pkg, strings.Join(changes[pkg], "\n"))
+	}
+	buf.WriteString("<!-- Auto generated by kcl-doc tool, please do not edit. -->\n")
+	return buf.String()
+}
+
+// renderChangelog writes the changelog fragment of the ChangelogSince version into the target directory
+func (g *GenContext) renderChangelog(spec *SwaggerV2Spec) error {
+	return g.writeOutput(g.Target, changelogDocFile, []byte(MarkdownChangelogSince(spec, g.ChangelogSince)))
+}
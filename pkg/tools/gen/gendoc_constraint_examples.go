@@ -0,0 +1,269 @@
+package gen
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// regexMatchRegexp matches the pattern checks such as `regex.match(name, r"^[a-z]+$")`
+var regexMatchRegexp = regexp.MustCompile(`^regex\.match\(\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*,\s*r?("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')\s*\)$`)
+
+// constraintExample is the valid and the invalid values of an attribute, and the explanation of the invalid value
+type constraintExample struct {
+	Valid   string
+	Invalid string
+	Reason  string
+}
+
+// attributeConstraints is the constraints of an attribute derived from the unconditional schema check expressions
+type attributeConstraints struct {
+	Min, Max       *int
+	MinLen, MaxLen *int
+	Pattern        string
+}
+
+// collectAttributeConstraints derives the bounds, the lengths and the pattern of the attribute from the checks
+func collectAttributeConstraints(schema *KclOpenAPIType, name string) *attributeConstraints {
+	c := &attributeConstraints{}
+	prop := schema.Properties[name]
+	c.MinLen, c.MaxLen = prop.MinItems, prop.MaxItems
+	if schema.KclExtensions == nil {
+		return c
+	}
+	for _, check := range schema.KclExtensions.XKclChecks {
+		if check.Condition != "" {
+			continue
+		}
+		if m := regexMatchRegexp.FindStringSubmatch(check.Expr); m != nil && m[1] == name {
+			if pattern, ok := kclStringLiteral(m[2]); ok {
+				c.Pattern = pattern
+			}
+			continue
+		}
+		for _, comparison := range parseComparisons(check.Expr) {
+			min, max := comparison.intBounds(name)
+			c.Min, c.Max = mergeBound(c.Min, min), mergeBound(c.Max, max)
+			min, max = comparison.intBounds("len(" + name + ")")
+			c.MinLen, c.MaxLen = mergeBound(c.MinLen, min), mergeBound(c.MaxLen, max)
+		}
+	}
+	return c
+}
+
+// kclStringLiteral returns the value of the quoted KCL string literal. The raw strings are not unescaped
+func kclStringLiteral(lit string) (string, bool) {
+	if len(lit) < 2 {
+		return "", false
+	}
+	return lit[1 : len(lit)-1], true
+}
+
+// attributeConstraintExample derives the valid and the invalid examples of the attribute from its enum members, its
+// numeric bounds, its pattern with the length bounds or its length bounds, in the order
+func attributeConstraintExample(schema *KclOpenAPIType, name string) *constraintExample {
+	prop, ok := schema.Properties[name]
+	if !ok {
+		return nil
+	}
+	if members := enumMembers(prop); len(members) > 0 {
+		e := &constraintExample{Valid: members[0]}
+		if invalid, ok := nonMember(members); ok {
+			e.Invalid, e.Reason = invalid, "not one of the allowed values"
+		}
+		return e
+	}
+	c := collectAttributeConstraints(schema, name)
+	switch {
+	case (prop.Type == Integer || prop.Type == Number) && (c.Min != nil || c.Max != nil):
+		if c.Max != nil {
+			valid := *c.Max
+			if c.Min != nil && *c.Min > valid {
+				valid = *c.Min
+			}
+			return &constraintExample{Valid: strconv.Itoa(valid), Invalid: strconv.Itoa(*c.Max + 1), Reason: fmt.Sprintf("exceeds maximum %d", *c.Max)}
+		}
+		return &constraintExample{Valid: strconv.Itoa(*c.Min), Invalid: strconv.Itoa(*c.Min - 1), Reason: fmt.Sprintf("below minimum %d", *c.Min)}
+	case prop.Type == String && c.Pattern != "":
+		return patternConstraintExample(c)
+	case (prop.Type == String || prop.Type == Array) && (c.MinLen != nil || c.MaxLen != nil):
+		sized := func(n int) string {
+			if prop.Type == String {
+				return strconv.Quote(strings.Repeat("a", n))
+			}
+			return "[" + strings.TrimSuffix(strings.Repeat("1, ", n), ", ") + "]"
+		}
+		if c.MinLen != nil && *c.MinLen > 0 {
+			return &constraintExample{Valid: sized(*c.MinLen), Invalid: sized(*c.MinLen - 1), Reason: fmt.Sprintf("shorter than minimum length %d", *c.MinLen)}
+		}
+		if c.MaxLen != nil {
+			return &constraintExample{Valid: sized(*c.MaxLen), Invalid: sized(*c.MaxLen + 1), Reason: fmt.Sprintf("longer than maximum length %d", *c.MaxLen)}
+		}
+	}
+	return nil
+}
+
+// patternConstraintExample derives the examples of the string attribute from its pattern. With the length bounds,
+// the valid value generated from the pattern is padded or trimmed to the bounds, and the invalid value violates the
+// length bounds
+func patternConstraintExample(c *attributeConstraints) *constraintExample {
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return nil
+	}
+	valid, ok := patternExample(c.Pattern)
+	if !ok {
+		return nil
+	}
+	if n := len([]rune(valid)); c.MinLen != nil && n < *c.MinLen {
+		valid = resizeString(valid, *c.MinLen)
+	} else if c.MaxLen != nil && n > *c.MaxLen {
+		valid = resizeString(valid, *c.MaxLen)
+	}
+	if !re.MatchString(valid) {
+		return nil
+	}
+	e := &constraintExample{Valid: strconv.Quote(valid)}
+	switch {
+	case c.MinLen != nil && *c.MinLen > 0:
+		e.Invalid, e.Reason = strconv.Quote(resizeString(valid, *c.MinLen-1)), fmt.Sprintf("shorter than minimum length %d", *c.MinLen)
+	case c.MaxLen != nil:
+		e.Invalid, e.Reason = strconv.Quote(resizeString(valid, *c.MaxLen+1)), fmt.Sprintf("longer than maximum length %d", *c.MaxLen)
+	default:
+		for _, candidate := range []string{"", "!", valid + "!", "!" + valid} {
+			if !re.MatchString(candidate) {
+				e.Invalid, e.Reason = strconv.Quote(candidate), fmt.Sprintf("does not match the pattern %s", c.Pattern)
+				break
+			}
+		}
+	}
+	return e
+}
+
+// resizeString trims the string to n characters, or pads it to n characters by repeating its last character
+func resizeString(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) >= n {
+		return string(runes[:n])
+	}
+	pad := 'a'
+	if len(runes) > 0 {
+		pad = runes[len(runes)-1]
+	}
+	return s + strings.Repeat(string(pad), n-len(runes))
+}
+
+// enumMembers returns the literals of the enum attribute, which is a literal type or a union of the literal types
+func enumMembers(tpe *KclOpenAPIType) []string {
+	if len(tpe.Enum) > 0 {
+		return tpe.Enum
+	}
+	if tpe.KclExtensions == nil || len(tpe.KclExtensions.XKclUnionTypes) == 0 {
+		return nil
+	}
+	var members []string
+	for _, t := range tpe.KclExtensions.XKclUnionTypes {
+		if len(t.Enum) == 0 {
+			return nil
+		}
+		members = append(members, t.Enum...)
+	}
+	return members
+}
+
+// nonMember returns a literal of the same type as the enum members which is not any member. The enums of the mixed
+// types and the bool enums have no such literal
+func nonMember(members []string) (string, bool) {
+	set := map[string]bool{}
+	allStrings, allNumbers, max := true, true, 0.0
+	for i, m := range members {
+		set[m] = true
+		allStrings = allStrings && strings.HasPrefix(m, `"`)
+		v, err := strconv.ParseFloat(m, 64)
+		allNumbers = allNumbers && err == nil
+		if err == nil && (i == 0 || v > max) {
+			max = v
+		}
+	}
+	switch {
+	case allStrings:
+		for s := "invalid"; ; s += "_" {
+			if lit := strconv.Quote(s); !set[lit] {
+				return lit, true
+			}
+		}
+	case allNumbers:
+		return strconv.FormatFloat(max+1, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// patternExample generates a short string matching the pattern, which takes the first alternative, the first rune
+// of the character classes and the minimum repetitions
+func patternExample(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpLiteral:
+			b.WriteString(string(re.Rune))
+		case syntax.OpCharClass:
+			if len(re.Rune) == 0 {
+				return false
+			}
+			b.WriteRune(re.Rune[0])
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			b.WriteRune('a')
+		case syntax.OpCapture:
+			return walk(re.Sub[0])
+		case syntax.OpPlus:
+			return walk(re.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < re.Min; i++ {
+				if !walk(re.Sub[0]) {
+					return false
+				}
+			}
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+		case syntax.OpAlternate:
+			return walk(re.Sub[0])
+		case syntax.OpStar, syntax.OpQuest, syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+			syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(re) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// constraintExamples renders the valid and the invalid examples of the attribute, such as
+// "Valid: `443`<br />Invalid: `70000` (exceeds maximum 65535)"
+func constraintExamples(schema *KclOpenAPIType, name string) string {
+	e := attributeConstraintExample(schema, name)
+	if e == nil {
+		return ""
+	}
+	code := func(s string) string {
+		return "`" + strings.ReplaceAll(s, "|", "\\|") + "`"
+	}
+	text := fmt.Sprintf("Valid: %s", code(e.Valid))
+	if e.Invalid != "" {
+		text += fmt.Sprintf("<br />Invalid: %s (%s)", code(e.Invalid), strings.ReplaceAll(e.Reason, "|", "\\|"))
+	}
+	return text
+}
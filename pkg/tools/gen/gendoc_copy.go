@@ -0,0 +1,79 @@
+package gen
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// codeBlockRegexp matches the code blocks rendered by goldmark, such as the schema examples
+var codeBlockRegexp = regexp.MustCompile(`(?s)<pre><code[^>]*>.*?</code></pre>`)
+
+// typeSpanRegexp matches the opening tags of the attribute type spans rendered by addTypeExpressionTitles, whose
+// title is the type expression as authored
+var typeSpanRegexp = regexp.MustCompile(`<span class="kcl-type" title="([^"]*)">`)
+
+// copyButton is the button markup inserted before each code block. The status is announced by the screen readers
+const copyButton = `<div class="kcl-copy-block"><button type="button" class="kcl-copy-button" aria-label="Copy to clipboard" title="Copy to clipboard">Copy</button><span class="kcl-copy-status" role="status" aria-live="polite"></span>`
+
+// typeCopyButton is the inline button markup inserted before each attribute type span, which copies the type
+// expression in its data-copy attribute
+const typeCopyButton = `<span class="kcl-copy-block kcl-copy-inline" data-copy="$1"><button type="button" class="kcl-copy-button" aria-label="Copy the type to clipboard" title="Copy the type to clipboard">Copy</button><span class="kcl-copy-status" role="status" aria-live="polite"></span></span>$0`
+
+// copyButtonAssets is the style and script of the copy buttons bundled in the page, so the offline docs work
+// without any external dependency. The clipboard API falls back to the selection copy for the file:// pages
+const copyButtonAssets = `<style>
+.kcl-copy-block { position: relative; }
+.kcl-copy-button { position: absolute; top: 4px; right: 4px; }
+.kcl-copy-inline { display: inline-block; }
+.kcl-copy-inline .kcl-copy-button { position: static; }
+.kcl-copy-status { position: absolute; width: 1px; height: 1px; overflow: hidden; clip: rect(0 0 0 0); }
+</style>
+<script>
+(function () {
+  function fallbackCopy(text) {
+    var area = document.createElement("textarea");
+    area.value = text;
+    area.setAttribute("readonly", "");
+    area.style.position = "absolute";
+    area.style.left = "-9999px";
+    document.body.appendChild(area);
+    area.select();
+    try { document.execCommand("copy"); } finally { document.body.removeChild(area); }
+  }
+  document.addEventListener("click", function (e) {
+    var button = e.target.closest(".kcl-copy-button");
+    if (!button) { return; }
+    var block = button.parentNode;
+    var text = block.hasAttribute("data-copy") ? block.getAttribute("data-copy") : block.querySelector("code").innerText;
+    var done = function () {
+      block.querySelector(".kcl-copy-status").textContent = "Copied";
+      button.textContent = "Copied";
+      setTimeout(function () { button.textContent = "Copy"; }, 2000);
+    };
+    if (navigator.clipboard && window.isSecureContext) {
+      navigator.clipboard.writeText(text).then(done, function () { fallbackCopy(text); done(); });
+    } else {
+      fallbackCopy(text);
+      done();
+    }
+  });
+})();
+</script>
+`
+
+// addCopyButtons wraps each code block of the html doc with a copy-to-clipboard button, inserts an inline button
+// before each attribute type span, and appends the bundled style and script if there is any code block or type span
+func addCopyButtons(content []byte) []byte {
+	if !codeBlockRegexp.Match(content) && !typeSpanRegexp.Match(content) {
+		return content
+	}
+	content = typeSpanRegexp.ReplaceAll(content, []byte(typeCopyButton))
+	content = codeBlockRegexp.ReplaceAllFunc(content, func(block []byte) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(copyButton)
+		buf.Write(block)
+		buf.WriteString("</div>")
+		return buf.Bytes()
+	})
+	return append(content, copyButtonAssets...)
+}
@@ -0,0 +1,157 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// maxDefaultsShapeDepth is the cap of the nesting depth of the required schema attributes in the defaults shape
+const maxDefaultsShapeDepth = 8
+
+// unitPlaceholder is the placeholder of the quantity attributes, which is a KCL unit literal rather than a string
+type unitPlaceholder string
+
+// collectDefaultsShapes renders the defaults shape of each schema when ShowDefaultsShape is set, which is the YAML
+// of the instance with only the placeholders of the required attributes and all the defaults applied. The instance
+// is evaluated with the KCL runtime if available, otherwise the shape is approximated from the attribute defaults.
+func (g *GenContext) collectDefaultsShapes(spec *SwaggerV2Spec) {
+	g.defaultsShapes = map[string]string{}
+	if !g.ShowDefaultsShape {
+		return
+	}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		shape, err := g.evaluateDefaultsShape(spec, schema)
+		if err == nil {
+			g.defaultsShapes[id] = shape
+			continue
+		}
+		g.warnf("failed to evaluate the defaults shape of schema %s, the shape is approximated from the defaults: %s", id, err)
+		shape, err = approximateDefaultsShape(spec, schema)
+		if err != nil {
+			g.warnf("failed to render the defaults shape of schema %s: %s", id, err)
+			continue
+		}
+		g.defaultsShapes[id] = shape
+	}
+}
+
+// evaluateDefaultsShape evaluates the instance of the schema with the placeholders of the required attributes with
+// the KCL runtime, and returns the YAML result
+func (g *GenContext) evaluateDefaultsShape(spec *SwaggerV2Spec, schema *KclOpenAPIType) (string, error) {
+	placeholders := requiredPlaceholders(spec, schema, map[string]bool{schema.schemaId(): true})
+	code := fmt.Sprintf("%s = %s %s\n", exampleResultName, schema.KclExtensions.XKclModelType.Type, kclLiteral(placeholders))
+	return g.evaluateExample(schema, code)
+}
+
+// approximateDefaultsShape approximates the defaults shape of the schema without the KCL runtime: the attributes with
+// the literal defaults and the placeholders of the required attributes are kept, and the optional attributes
+// without defaults are omitted. The attributes are sorted by name.
+func approximateDefaultsShape(spec *SwaggerV2Spec, schema *KclOpenAPIType) (string, error) {
+	content, err := yaml.Marshal(defaultsShapeValue(spec, schema, map[string]bool{schema.schemaId(): true}))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// defaultsShapeValue returns the approximated instance of the schema with the defaults and the required placeholders
+func defaultsShapeValue(spec *SwaggerV2Spec, schema *KclOpenAPIType, path map[string]bool) yaml.MapSlice {
+	placeholders := requiredPlaceholders(spec, schema, path)
+	shape := yaml.MapSlice{}
+	for _, name := range getSortedKeys(schema.Properties) {
+		if value, ok := placeholders[name]; ok {
+			if nested, ok := schema.Properties[name].refSchema(spec); ok && !path[nested.schemaId()] {
+				// the nested schema instance has the defaults applied as well
+				path[nested.schemaId()] = true
+				value = defaultsShapeValue(spec, nested, path)
+				delete(path, nested.schemaId())
+			}
+			shape = append(shape, yaml.MapItem{Key: name, Value: value})
+			continue
+		}
+		if value, ok := kclDefaultValue(schema.Properties[name].Default); ok {
+			shape = append(shape, yaml.MapItem{Key: name, Value: value})
+		}
+	}
+	return shape
+}
+
+// kclDefaultValue converts the literal default to the value, which keeps the integers and the order of the dict keys
+func kclDefaultValue(lit string) (interface{}, bool) {
+	var value interface{}
+	if json.Valid([]byte(lit)) && yaml.UnmarshalWithOptions([]byte(lit), &value, yaml.UseOrderedMap()) == nil {
+		return value, true
+	}
+	return kclLiteralToJson(lit)
+}
+
+// requiredPlaceholders returns the placeholders of the required attributes without defaults of the schema. The
+// schemas already on the path are not followed again to stop on the cycles
+func requiredPlaceholders(spec *SwaggerV2Spec, schema *KclOpenAPIType, path map[string]bool) map[string]interface{} {
+	placeholders := map[string]interface{}{}
+	for _, name := range schema.Required {
+		prop, ok := schema.Properties[name]
+		if !ok || prop.Default != "" {
+			continue
+		}
+		placeholders[name] = placeholderValue(spec, prop, path)
+	}
+	return placeholders
+}
+
+// placeholderValue returns the type-appropriate placeholder of the required attribute
+func placeholderValue(spec *SwaggerV2Spec, tpe *KclOpenAPIType, path map[string]bool) interface{} {
+	if len(tpe.Enum) > 0 {
+		if v, ok := kclLiteralToJson(tpe.Enum[0]); ok {
+			return v
+		}
+	}
+	if nested, ok := tpe.refSchema(spec); ok {
+		if path[nested.schemaId()] || len(path) >= maxDefaultsShapeDepth {
+			return map[string]interface{}{}
+		}
+		path[nested.schemaId()] = true
+		defer delete(path, nested.schemaId())
+		return requiredPlaceholders(spec, nested, path)
+	}
+	switch tpe.Type {
+	case String:
+		if example, ok := tpe.formatExample(); ok {
+			return example
+		}
+		return "string"
+	case Integer:
+		if tpe.Format == NumberMultiplier {
+			return unitPlaceholder("1Ki")
+		}
+		return 1
+	case Number:
+		return 1.0
+	case Bool:
+		return true
+	case Array:
+		return []interface{}{}
+	case Object:
+		if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+			return placeholderValue(spec, tpe.KclExtensions.XKclUnionTypes[0], path)
+		}
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// refSchema returns the schema referenced by the type
+func (tpe *KclOpenAPIType) refSchema(spec *SwaggerV2Spec) (*KclOpenAPIType, bool) {
+	if tpe.Ref == "" {
+		return nil, false
+	}
+	schema, ok := spec.Definitions[Ref2SchemaId(tpe.Ref)]
+	return schema, ok
+}
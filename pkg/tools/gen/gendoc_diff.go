@@ -0,0 +1,273 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const migrationDocFile = "MIGRATION.md"
+
+// SpecDiff defines the changes between two versions of a KCL package
+type SpecDiff struct {
+	Breaking   []*SchemaChange // changes that may break the users of the old version
+	New        []*SchemaChange // additive changes
+	Deprecated []*SchemaChange // schemas and attributes deprecated in the new version
+}
+
+// SchemaChange defines a change of a schema or a schema attribute
+type SchemaChange struct {
+	Schema    string // the schema id in the old version, or in the new version if the schema is added
+	Attribute string // the attribute name, empty if the change is on the whole schema
+	Before    string // the attribute declaration in the old version
+	After     string // the attribute declaration in the new version
+	Message   string // the description of the change
+	Migration string // the suggested migration step
+}
+
+// DiffSwaggerV2Spec compares the schemas of two versions of a KCL package.
+// A new schema tagged with `@alias <OldSchemaId>` or `@replaces <OldSchemaId>` in its docstring, or an old schema
+// tagged with `@replaced-by <NewSchemaId>`, is treated as a rename of the old schema if the old schema is removed in
+// the new version. The old schemas kept in the new version are deprecated and replaced, not renamed.
+func DiffSwaggerV2Spec(old, updated *SwaggerV2Spec) *SpecDiff {
+	diff := &SpecDiff{}
+	renamed := map[string]string{}
+	for _, id := range getSortedKeys(updated.Definitions) {
+		for _, tag := range []string{"alias", "replaces"} {
+			for _, a := range annotationValues(updated.Definitions[id].Description, tag) {
+				_, inOld := old.Definitions[a]
+				_, inNew := updated.Definitions[a]
+				if inOld && !inNew {
+					renamed[a] = id
+				}
+			}
+		}
+	}
+	for _, id := range getSortedKeys(old.Definitions) {
+		if _, ok := updated.Definitions[id]; ok || renamed[id] != "" {
+			continue
+		}
+		for _, to := range annotationValues(old.Definitions[id].Description, "replaced-by") {
+			if _, ok := updated.Definitions[to]; ok {
+				renamed[id] = to
+				break
+			}
+		}
+	}
+	for _, id := range getSortedKeys(old.Definitions) {
+		oldSch := old.Definitions[id]
+		newId := id
+		if to, ok := renamed[id]; ok {
+			newId = to
+			diff.Breaking = append(diff.Breaking, &SchemaChange{
+				Schema:    id,
+				Message:   fmt.Sprintf("schema `%s` is renamed to `%s`", id, to),
+				Migration: fmt.Sprintf("replace the references of `%s` with `%s`", schemaShortName(id), schemaShortName(to)),
+			})
+		}
+		newSch, ok := updated.Definitions[newId]
+		if !ok {
+			diff.Breaking = append(diff.Breaking, &SchemaChange{
+				Schema:  id,
+				Message: fmt.Sprintf("schema `%s` is removed", id),
+			})
+			continue
+		}
+		diffSchema(diff, id, oldSch, newSch, renamed)
+	}
+	renamedTo := map[string]bool{}
+	for _, to := range renamed {
+		renamedTo[to] = true
+	}
+	for _, id := range getSortedKeys(updated.Definitions) {
+		if _, ok := old.Definitions[id]; ok || renamedTo[id] {
+			continue
+		}
+		diff.New = append(diff.New, &SchemaChange{
+			Schema:  id,
+			Message: fmt.Sprintf("schema `%s` is added", id),
+		})
+	}
+	return diff
+}
+
+// diffSchema compares the attributes of the schema in two versions. The attribute types are compared by their
+// structures and the referenced schema ids, with the old schema ids mapped to the renamed ones, so the changes of the
+// import aliases are not type changes
+func diffSchema(diff *SpecDiff, id string, oldSch, newSch *KclOpenAPIType, renamed map[string]string) {
+	if newSch.isDeprecated() && !oldSch.isDeprecated() {
+		diff.Deprecated = append(diff.Deprecated, &SchemaChange{
+			Schema:  id,
+			Message: fmt.Sprintf("schema `%s` is deprecated", id),
+		})
+	}
+	for _, name := range getSortedKeys(oldSch.Properties) {
+		oldAttr := oldSch.Properties[name]
+		before := attributeDeclaration(name, oldAttr, containsString(oldSch.Required, name))
+		newAttr, ok := newSch.Properties[name]
+		if !ok {
+			diff.Breaking = append(diff.Breaking, &SchemaChange{
+				Schema:    id,
+				Attribute: name,
+				Before:    before,
+				Message:   fmt.Sprintf("attribute `%s` is removed", name),
+			})
+			continue
+		}
+		after := attributeDeclaration(name, newAttr, containsString(newSch.Required, name))
+		oldType, newType := oldAttr.GetKclTypeName(false, false, false), newAttr.GetKclTypeName(false, false, false)
+		switch {
+		case typeSignature(oldAttr, renamed) != typeSignature(newAttr, nil):
+			diff.Breaking = append(diff.Breaking, &SchemaChange{
+				Schema:    id,
+				Attribute: name,
+				Before:    before,
+				After:     after,
+				Message:   fmt.Sprintf("the type of attribute `%s` is changed from `%s` to `%s`", name, oldType, newType),
+			})
+		case !containsString(oldSch.Required, name) && containsString(newSch.Required, name):
+			diff.Breaking = append(diff.Breaking, &SchemaChange{
+				Schema:    id,
+				Attribute: name,
+				Before:    before,
+				After:     after,
+				Message:   fmt.Sprintf("attribute `%s` becomes required", name),
+				Migration: fmt.Sprintf("set the attribute `%s` explicitly", name),
+			})
+		}
+		if newAttr.isDeprecated() && !oldAttr.isDeprecated() {
+			diff.Deprecated = append(diff.Deprecated, &SchemaChange{
+				Schema:    id,
+				Attribute: name,
+				Message:   fmt.Sprintf("attribute `%s` is deprecated", name),
+			})
+		}
+	}
+	for _, name := range getSortedKeys(newSch.Properties) {
+		if _, ok := oldSch.Properties[name]; ok {
+			continue
+		}
+		required := containsString(newSch.Required, name)
+		change := &SchemaChange{
+			Schema:    id,
+			Attribute: name,
+			After:     attributeDeclaration(name, newSch.Properties[name], required),
+		}
+		if required {
+			change.Message = fmt.Sprintf("required attribute `%s` is added", name)
+			change.Migration = fmt.Sprintf("set the attribute `%s` explicitly", name)
+			diff.Breaking = append(diff.Breaking, change)
+		} else {
+			change.Message = fmt.Sprintf("optional attribute `%s` is added", name)
+			diff.New = append(diff.New, change)
+		}
+	}
+}
+
+// typeSignature returns the structure of the type with the schema references as the schema ids, which are mapped
+// with the renamed schema ids if any, such as `[{str:models.Server}]`
+func typeSignature(tpe *KclOpenAPIType, renamed map[string]string) string {
+	if tpe == nil {
+		return ""
+	}
+	if tpe.Ref != "" {
+		id := Ref2SchemaId(tpe.Ref)
+		if to, ok := renamed[id]; ok {
+			id = to
+		}
+		return id
+	}
+	switch {
+	case tpe.ReadOnly && tpe.Default != "":
+		return fmt.Sprintf("%s(%s)", tpe.Type, tpe.Default)
+	case tpe.Type == Array:
+		return fmt.Sprintf("[%s]", typeSignature(tpe.Items, renamed))
+	case tpe.Type == Object && tpe.AdditionalProperties != nil:
+		var key *KclOpenAPIType
+		if tpe.KclExtensions != nil {
+			key = tpe.KclExtensions.XKclDictKeyType
+		}
+		return fmt.Sprintf("{%s:%s}", typeSignature(key, renamed), typeSignature(tpe.AdditionalProperties, renamed))
+	case tpe.Type == Object && tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0:
+		members := make([]string, len(tpe.KclExtensions.XKclUnionTypes))
+		for i, t := range tpe.KclExtensions.XKclUnionTypes {
+			members[i] = typeSignature(t, renamed)
+		}
+		return strings.Join(members, "|")
+	}
+	return fmt.Sprintf("%s(%s)", tpe.Type, tpe.Format)
+}
+
+// attributeDeclaration returns the KCL declaration of the attribute, such as `name?: str = "default"`
+func attributeDeclaration(name string, tpe *KclOpenAPIType, required bool) string {
+	decl := name
+	if !required {
+		decl += "?"
+	}
+	decl += ": " + tpe.GetKclTypeName(false, false, false)
+	if tpe.Default != "" && !tpe.ReadOnly {
+		decl += " = " + tpe.Default
+	}
+	return decl
+}
+
+// MarkdownMigrationGuide renders the diff to a migration guide in markdown
+func (d *SpecDiff) MarkdownMigrationGuide() string {
+	var buf bytes.Buffer
+	buf.WriteString("# Migration Guide\n")
+	sections := []struct {
+		title   string
+		changes []*SchemaChange
+	}{
+		{"Breaking Changes", d.Breaking},
+		{"New", d.New},
+		{"Deprecated", d.Deprecated},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&buf, "\n## %s\n\n", section.title)
+		if section.title == "Breaking Changes" && len(section.changes) > 0 {
+			buf.WriteString("> **Warning**: the following changes are not backward compatible, the configurations based on the previous version need to be updated.\n\n")
+		}
+		if len(section.changes) == 0 {
+			buf.WriteString("None.\n")
+			continue
+		}
+		for _, change := range section.changes {
+			fmt.Fprintf(&buf, "- `%s`: %s\n", change.Schema, change.Message)
+			if change.Before != "" || change.After != "" {
+				buf.WriteString("\n  ```\n")
+				if change.Before != "" {
+					fmt.Fprintf(&buf, "  # before\n  %s\n", change.Before)
+				}
+				if change.After != "" {
+					fmt.Fprintf(&buf, "  # after\n  %s\n", change.After)
+				}
+				buf.WriteString("  ```\n\n")
+			}
+			if change.Migration != "" {
+				fmt.Fprintf(&buf, "  - **Migration**: %s\n", change.Migration)
+			}
+		}
+	}
+	buf.WriteString("\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n")
+	return buf.String()
+}
+
+// renderMigrationGuide writes the migration guide from the old spec to the new spec into the target directory
+func (g *GenContext) renderMigrationGuide(old, new *SwaggerV2Spec) error {
+	diff := DiffSwaggerV2Spec(old, new)
+	return g.writeOutput(g.Target, migrationDocFile, g.withToolHeader(migrationDocFile, []byte(diff.MarkdownMigrationGuide())))
+}
+
+func schemaShortName(id string) string {
+	return id[strings.LastIndex(id, ".")+1:]
+}
+
+func containsString(list []string, elem string) bool {
+	for _, s := range list {
+		if s == elem {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func newTestSchema(name string, description string, required []string, properties map[string]*KclOpenAPIType) *KclOpenAPIType {
+	return &KclOpenAPIType{
+		Type:        Object,
+		Description: description,
+		Properties:  properties,
+		Required:    required,
+		KclExtensions: &KclExtensions{
+			XKclModelType: &XKclModelType{
+				Type:   name,
+				Import: &KclModelImportInfo{},
+			},
+		},
+	}
+}
+
+func TestDiffSwaggerV2Spec(t *testing.T) {
+	old := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Person": newTestSchema("Person", "", []string{"name"}, map[string]*KclOpenAPIType{
+				"name":     {Type: String},
+				"age":      {Type: Integer, Format: Int64},
+				"nickname": {Type: String},
+			}),
+			"Pet": newTestSchema("Pet", "", nil, map[string]*KclOpenAPIType{
+				"name": {Type: String},
+			}),
+			"Legacy": newTestSchema("Legacy", "", nil, nil),
+		},
+	}
+	new := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Person": newTestSchema("Person", "", []string{"name", "email"}, map[string]*KclOpenAPIType{
+				"name":  {Type: String},
+				"age":   {Type: String},
+				"email": {Type: String},
+				"phone": {Type: String, Default: `"-"`},
+			}),
+			"Animal": newTestSchema("Animal", "An animal.\n@alias Pet", nil, map[string]*KclOpenAPIType{
+				"name": {
+					Type: String,
+					KclExtensions: &KclExtensions{
+						XKclDecorators: XKclDecorators{{Name: "deprecated"}},
+					},
+				},
+			}),
+		},
+	}
+	diff := DiffSwaggerV2Spec(old, new)
+
+	var breaking, added, deprecated []string
+	for _, c := range diff.Breaking {
+		breaking = append(breaking, c.Message)
+	}
+	for _, c := range diff.New {
+		added = append(added, c.Message)
+	}
+	for _, c := range diff.Deprecated {
+		deprecated = append(deprecated, c.Message)
+	}
+	assert2.Equal(t, []string{
+		"schema `Legacy` is removed",
+		"the type of attribute `age` is changed from `int` to `str`",
+		"attribute `nickname` is removed",
+		"required attribute `email` is added",
+		"schema `Pet` is renamed to `Animal`",
+	}, breaking)
+	assert2.Equal(t, []string{"optional attribute `phone` is added"}, added)
+	assert2.Equal(t, []string{"attribute `name` is deprecated"}, deprecated)
+
+	g := newTestGenContext(t, Markdown)
+	if err := g.renderMigrationGuide(old, new); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.Target, migrationDocFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+	assert2.Contains(t, got, "## Breaking Changes\n\n> **Warning**")
+	assert2.Contains(t, got, "  # before\n  age?: int\n  # after\n  age?: str\n")
+	assert2.Contains(t, got, "  - **Migration**: replace the references of `Pet` with `Animal`\n")
+	assert2.Contains(t, got, "## New\n\n- `Person`: optional attribute `phone` is added\n\n  ```\n  # after\n  phone?: str = \"-\"\n  ```\n")
+}
+
+func TestDiffSwaggerV2SpecReplacement(t *testing.T) {
+	old := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Pet":    newTestSchema("Pet", "", nil, nil),
+			"Host":   newTestSchema("Host", "A host.\n@replaced-by Server", nil, nil),
+			"Legacy": newTestSchema("Legacy", "@replaced-by Missing", nil, nil),
+		},
+	}
+	new := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Animal": newTestSchema("Animal", "An animal.\n@replaces Pet", nil, nil),
+			"Server": newTestSchema("Server", "A server.", nil, nil),
+		},
+	}
+	var breaking []string
+	for _, c := range DiffSwaggerV2Spec(old, new).Breaking {
+		breaking = append(breaking, c.Message)
+	}
+	assert2.Equal(t, []string{
+		"schema `Host` is renamed to `Server`",
+		"schema `Legacy` is removed",
+		"schema `Pet` is renamed to `Animal`",
+	}, breaking)
+
+	// the guide is rendered in memory without writing to the disk
+	g := newTestGenContext(t, Markdown)
+	g.memoryOutputs = &memoryOutputs{root: g.Target, files: map[string][]byte{}}
+	assert2.NoError(t, g.renderMigrationGuide(old, new))
+	assert2.Contains(t, string(g.memoryOutputs.files[migrationDocFile]), "  - **Migration**: replace the references of `Host` with `Server`\n")
+	_, err := os.Stat(g.Target)
+	assert2.True(t, os.IsNotExist(err))
+
+	// the replaced schema kept in the new version is not renamed, and the changes of the import aliases and the
+	// references to the renamed schemas are not type changes
+	withAlias := func(ref string, alias string) *KclOpenAPIType {
+		return &KclOpenAPIType{Ref: ref, KclExtensions: &KclExtensions{XKclImportAlias: alias}}
+	}
+	old = &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Pet":  newTestSchema("Pet", "", nil, nil),
+			"Host": newTestSchema("Host", "", nil, nil),
+			"App": newTestSchema("App", "", nil, map[string]*KclOpenAPIType{
+				"owner": withAlias("#/definitions/models.Person", "m"),
+				"hosts": {Type: Array, Items: &KclOpenAPIType{Ref: "#/definitions/Host"}},
+			}),
+		},
+	}
+	updated := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Pet":    newTestSchema("Pet", "", nil, nil),
+			"Animal": newTestSchema("Animal", "@replaces Pet", nil, nil),
+			"Server": newTestSchema("Server", "@alias Host", nil, nil),
+			"App": newTestSchema("App", "", nil, map[string]*KclOpenAPIType{
+				"owner": withAlias("#/definitions/models.Person", "mdl"),
+				"hosts": {Type: Array, Items: &KclOpenAPIType{Ref: "#/definitions/Server"}},
+			}),
+		},
+	}
+	diff := DiffSwaggerV2Spec(old, updated)
+	breaking = nil
+	for _, c := range diff.Breaking {
+		breaking = append(breaking, c.Message)
+	}
+	assert2.Equal(t, []string{"schema `Host` is renamed to `Server`"}, breaking)
+	assert2.Equal(t, "schema `Animal` is added", diff.New[0].Message)
+}
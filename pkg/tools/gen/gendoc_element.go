@@ -0,0 +1,32 @@
+package gen
+
+// collectSchemaSummaries collects the first sentence of the description of each schema when ShowElementSummary is
+// set, which is rendered next to the list and dict attributes of the schema elements
+func (g *GenContext) collectSchemaSummaries(spec *SwaggerV2Spec) {
+	g.schemaSummaries = map[string]string{}
+	if !g.ShowElementSummary {
+		return
+	}
+	for id, schema := range spec.Definitions {
+		if summary := firstSentence(g.localizedDocText(schema.Description)); summary != "" {
+			g.schemaSummaries[id] = summary
+		}
+	}
+}
+
+// elementSummary returns the summary of the element schema of the list or dict attribute
+func (g *GenContext) elementSummary(tpe *KclOpenAPIType) string {
+	var element *KclOpenAPIType
+	switch {
+	case tpe.Type == Array && tpe.Items != nil:
+		element = tpe.Items
+	case tpe.Type == Object && tpe.AdditionalProperties != nil:
+		element = tpe.AdditionalProperties
+	default:
+		return ""
+	}
+	if element.Ref == "" {
+		return ""
+	}
+	return g.schemaSummaries[Ref2SchemaId(element.Ref)]
+}
@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	kcl "kcl-lang.io/kcl-go"
+)
+
+const (
+	exampleFileName   = "__example__.k"
+	exampleResultName = "__example__"
+)
+
+// evaluateSchemaExamples evaluates the schema examples with the KCL runtime and records the results, so the docs
+// show the effect of the attribute defaults. The examples which fail to evaluate, e.g. the KCL runtime is not
+// available, are reported as warnings and rendered without the results.
+func (g *GenContext) evaluateSchemaExamples(spec *SwaggerV2Spec) {
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if len(schema.Examples) == 0 || schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		for _, name := range getSortedKeys(schema.Examples) {
+			example := schema.Examples[name]
+			code := example.Value
+			if strings.HasPrefix(name, schemaAnnotationExample) {
+				// the `@example` annotation is an instance of the schema in YAML
+				var err error
+				code, err = exampleInstanceCode(schema.KclExtensions.XKclModelType.Type, example.Value)
+				if err != nil {
+					g.warnf("failed to evaluate the example %s of schema %s: %s", name, id, err)
+					continue
+				}
+			}
+			result, err := g.evaluateExample(schema, code)
+			if err != nil {
+				g.warnf("failed to evaluate the example %s of schema %s: %s", name, id, err)
+				continue
+			}
+			example.Evaluated = result
+			schema.Examples[name] = example
+		}
+	}
+}
+
+// evaluateExample runs the example code together with all the source files of the schema package and returns the
+// YAML result, so the schemas using the other files of the package and the imports are evaluated. The test files
+// are not run. The imports are resolved from the package root
+func (g *GenContext) evaluateExample(schema *KclOpenAPIType, code string) (result string, err error) {
+	defer func() {
+		// the KCL runtime may be not available
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	pkgDir := schema.GetSchemaPkgDir(g.PackagePath)
+	entries, err := os.ReadDir(pkgDir)
+	if err != nil {
+		return "", err
+	}
+	var files, codes []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".k") || strings.HasSuffix(name, "_test.k") || name == exampleFileName {
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(pkgDir, name))
+		if err != nil {
+			return "", err
+		}
+		files, codes = append(files, filepath.Join(pkgDir, name)), append(codes, string(source))
+	}
+	files, codes = append(files, filepath.Join(pkgDir, exampleFileName)), append(codes, code)
+	r, err := kcl.RunFiles(files, kcl.WithCode(codes...), kcl.WithWorkDir(g.PackagePath))
+	if err != nil {
+		return "", err
+	}
+	result = strings.TrimSpace(r.GetRawYamlResult())
+	if prefix := exampleResultName + ":\n"; strings.HasPrefix(result, prefix) {
+		// only keep the instance of the `@example` annotation
+		var lines []string
+		for _, line := range strings.Split(strings.TrimPrefix(result, prefix), "\n") {
+			lines = append(lines, strings.TrimPrefix(line, "  "))
+		}
+		result = strings.Join(lines, "\n")
+	}
+	return result, nil
+}
+
+// exampleInstanceCode returns the KCL code which instantiates the schema with the YAML instance
+func exampleInstanceCode(schemaName string, instance string) (string, error) {
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(instance), &value); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s = %s %s\n", exampleResultName, schemaName, kclLiteral(value)), nil
+}
+
+// kclLiteral converts the YAML value to the KCL literal
+func kclLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = kclLiteral(item)
+		}
+		return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+	case map[string]interface{}:
+		keys := getSortedKeys(v)
+		entries := make([]string, len(keys))
+		for i, k := range keys {
+			entries[i] = fmt.Sprintf("%s: %s", strconv.Quote(k), kclLiteral(v[k]))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(entries, ", "))
+	}
+	return fmt.Sprint(value)
+}
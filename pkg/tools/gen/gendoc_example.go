@@ -0,0 +1,247 @@
+package gen
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// schemaAnnotationExample is the example name of the `@example` annotation in the schema docstring
+const schemaAnnotationExample = "Annotation example"
+
+// collectSchemaExamples adds the `@example` instance literals in the schema docstrings to the schema examples.
+// The examples are validated against the schema, the mismatches fail the generation if ValidateSchemaExamples is set,
+// otherwise they are reported as warnings.
+func (g *GenContext) collectSchemaExamples(spec *SwaggerV2Spec) error {
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		for i, example := range annotationValues(schema.Description, "example") {
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(example), &value); err != nil {
+				if g.ValidateSchemaExamples {
+					return fmt.Errorf("invalid example of schema %s: %s", id, err)
+				}
+				g.warnf("invalid example of schema %s: %s", id, err)
+			} else if errs := validateInstance(spec, schema, value, ""); len(errs) > 0 {
+				if g.ValidateSchemaExamples {
+					return fmt.Errorf("the example of schema %s does not match the schema: %s", id, strings.Join(errs, "; "))
+				}
+				g.warnf("the example of schema %s does not match the schema: %s", id, strings.Join(errs, "; "))
+			}
+			if schema.Examples == nil {
+				schema.Examples = map[string]KclExample{}
+			}
+			name := schemaAnnotationExample
+			if i > 0 {
+				name = fmt.Sprintf("%s %d", schemaAnnotationExample, i+1)
+			}
+			schema.Examples[name] = KclExample{Value: example}
+		}
+	}
+	return nil
+}
+
+// validateInstance validates the value against the kcl type and returns the mismatches. It checks the information
+// in the KclOpenAPIType such as the attribute types, the required attributes and the enums, and the bounds, lengths
+// and patterns of the attributes derived from the schema checks. The other check expressions are not evaluated.
+func validateInstance(spec *SwaggerV2Spec, tpe *KclOpenAPIType, value interface{}, path string) []string {
+	mismatch := func(format string, args ...interface{}) []string {
+		where := path
+		if where == "" {
+			where = "the instance"
+		}
+		return []string{fmt.Sprintf("%s: %s", where, fmt.Sprintf(format, args...))}
+	}
+	if value == nil {
+		// None is valid for all the types, the required attributes set to None are checked by the schema
+		return nil
+	}
+	if tpe.Ref != "" {
+		ref, ok := spec.Definitions[Ref2SchemaId(tpe.Ref)]
+		if !ok {
+			return nil
+		}
+		return validateInstance(spec, ref, value, path)
+	}
+	if tpe.ReadOnly && len(tpe.Enum) > 0 {
+		lit, ok := kclLiteralToJson(tpe.Enum[0])
+		if ok && !equalJsonValue(lit, value) {
+			return mismatch("expect %s, got %v", tpe.Enum[0], value)
+		}
+		return nil
+	}
+	switch tpe.Type {
+	case String:
+		if _, ok := value.(string); !ok {
+			return mismatch("expect str, got %v", value)
+		}
+	case Integer:
+		if _, ok := value.(string); ok && tpe.Format == NumberMultiplier {
+			return nil
+		}
+		if f, ok := toFloat(value); !ok || f != math.Trunc(f) {
+			return mismatch("expect int, got %v", value)
+		}
+	case Number:
+		if _, ok := toFloat(value); !ok {
+			return mismatch("expect float, got %v", value)
+		}
+	case Bool:
+		if _, ok := value.(bool); !ok {
+			return mismatch("expect bool, got %v", value)
+		}
+	case Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch("expect list, got %v", value)
+		}
+		var errs []string
+		for i, item := range items {
+			errs = append(errs, validateInstance(spec, tpe.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case Object:
+		if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+			for _, t := range tpe.KclExtensions.XKclUnionTypes {
+				if len(validateInstance(spec, t, value, path)) == 0 {
+					return nil
+				}
+			}
+			return mismatch("%v does not match any of the union types", value)
+		}
+		if tpe.isAnyType() {
+			return nil
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch("expect dict, got %v", value)
+		}
+		var errs []string
+		if tpe.AdditionalProperties != nil {
+			for _, k := range getSortedKeys(m) {
+				errs = append(errs, validateInstance(spec, tpe.AdditionalProperties, m[k], joinAttrPath(path, k))...)
+			}
+			return errs
+		}
+		required := append([]string{}, tpe.Required...)
+		sort.Strings(required)
+		for _, name := range required {
+			v, ok := m[name]
+			if ok && v == nil {
+				// the required attribute can not be None even if it has a default value
+				errs = append(errs, mismatch("attribute '%s' is required and can't be None", name)...)
+				continue
+			}
+			if prop, hasProp := tpe.Properties[name]; hasProp && prop.Default != "" {
+				// the required attribute with a default value can be omitted
+				continue
+			}
+			if !ok {
+				errs = append(errs, mismatch("attribute '%s' is required", name)...)
+			}
+		}
+		for _, k := range getSortedKeys(m) {
+			prop, ok := tpe.Properties[k]
+			if !ok {
+				errs = append(errs, mismatch("attribute '%s' is not defined in the schema", k)...)
+				continue
+			}
+			attrErrs := validateInstance(spec, prop, m[k], joinAttrPath(path, k))
+			if len(attrErrs) == 0 {
+				attrErrs = validateConstraints(tpe, k, m[k], joinAttrPath(path, k))
+			}
+			errs = append(errs, attrErrs...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// validateConstraints validates the value of the schema attribute against the enum members of the attribute and the
+// bounds, the lengths and the `regex.match` patterns derived from the unconditional schema checks
+func validateConstraints(schema *KclOpenAPIType, name string, value interface{}, path string) []string {
+	if value == nil {
+		return nil
+	}
+	mismatch := func(format string, args ...interface{}) []string {
+		return []string{fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...))}
+	}
+	var errs []string
+	if prop := schema.Properties[name]; !prop.ReadOnly && len(prop.Enum) > 0 {
+		member := false
+		for _, e := range prop.Enum {
+			if lit, ok := kclLiteralToJson(e); ok && equalJsonValue(lit, value) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			errs = append(errs, mismatch("%v is not one of the allowed values %s", value, strings.Join(prop.Enum, ", "))...)
+		}
+	}
+	c := collectAttributeConstraints(schema, name)
+	if f, ok := toFloat(value); ok {
+		if c.Min != nil && f < float64(*c.Min) {
+			errs = append(errs, mismatch("%v is below minimum %d", value, *c.Min)...)
+		}
+		if c.Max != nil && f > float64(*c.Max) {
+			errs = append(errs, mismatch("%v exceeds maximum %d", value, *c.Max)...)
+		}
+	}
+	length := -1
+	switch v := value.(type) {
+	case string:
+		length = len([]rune(v))
+	case []interface{}:
+		length = len(v)
+	case map[string]interface{}:
+		length = len(v)
+	}
+	if length >= 0 && c.MinLen != nil && length < *c.MinLen {
+		errs = append(errs, mismatch("length %d is shorter than minimum length %d", length, *c.MinLen)...)
+	}
+	if length >= 0 && c.MaxLen != nil && length > *c.MaxLen {
+		errs = append(errs, mismatch("length %d is longer than maximum length %d", length, *c.MaxLen)...)
+	}
+	if s, ok := value.(string); ok && c.Pattern != "" {
+		if re, err := regexp.Compile(c.Pattern); err == nil && !re.MatchString(s) {
+			errs = append(errs, mismatch("%q does not match the pattern %s", s, c.Pattern)...)
+		}
+	}
+	return errs
+}
+
+func joinAttrPath(path string, attr string) string {
+	if path == "" {
+		return attr
+	}
+	return path + "." + attr
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func equalJsonValue(a, b interface{}) bool {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		return fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,125 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// indexItemRegexp matches the schema items of the html index, which link to the schema anchors
+var indexItemRegexp = regexp.MustCompile(`<li><a href="#([^"]+)">`)
+
+// indexHeading is the heading of the index in the html docs, which the facet controls follow
+const indexHeading = "<h2>Index</h2>\n"
+
+// facetsScript is the script of the facet filter bundled in the page, which hides the index items not matching the
+// selected facets and the packages without any visible schema, so the filter works without a server
+const facetsScript = `<script>
+(function () {
+  var controls = document.querySelectorAll(".kcl-facets select");
+  function apply() {
+    var items = document.querySelectorAll("li[data-stability]");
+    items.forEach(function (item) {
+      var visible = true;
+      controls.forEach(function (control) {
+        var value = control.value;
+        if (!value) { return; }
+        var values = (item.getAttribute("data-" + control.getAttribute("data-facet")) || "").split(" ");
+        visible = visible && values.indexOf(value) >= 0;
+      });
+      item.hidden = !visible;
+    });
+    var packages = Array.prototype.slice.call(document.querySelectorAll(".kcl-facets + ul li:not([data-stability])")).reverse();
+    packages.forEach(function (item) {
+      var schemas = item.querySelectorAll("li[data-stability]");
+      item.hidden = schemas.length > 0 && Array.prototype.every.call(schemas, function (s) { return s.hidden; });
+    });
+  }
+  controls.forEach(function (control) { control.addEventListener("change", apply); });
+})();
+</script>
+`
+
+// schemaFacets is the facet values of a schema
+type schemaFacets struct {
+	Stability string
+	Tags      []string
+}
+
+// schemaTags returns the tags of the schema declared with the `@tag` tags, each of which may list the tags separated by
+// commas, such as `@tag network, storage`
+func (tpe *KclOpenAPIType) schemaTags() []string {
+	var tags []string
+	for _, value := range annotationValues(tpe.Description, "tag") {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, strings.Join(strings.Fields(tag), "-"))
+			}
+		}
+	}
+	return tags
+}
+
+// addFacets adds the facet data attributes of the stability and the tags to the schema items of the html index, and
+// the facet controls with the bundled filter script after the index heading
+func addFacets(content []byte, spec *SwaggerV2Spec) []byte {
+	if !bytes.Contains(content, []byte(indexHeading)) {
+		return content
+	}
+	facets := map[string]schemaFacets{}
+	stabilities := map[string]bool{}
+	tags := map[string]bool{}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		f := schemaFacets{Stability: schema.stability(), Tags: schema.schemaTags()}
+		stabilities[f.Stability] = true
+		for _, tag := range f.Tags {
+			tags[tag] = true
+		}
+		anchor := strings.ToLower(schema.KclExtensions.XKclModelType.Type)
+		if existing, ok := facets[anchor]; ok {
+			// the schemas of the same name in different packages share the anchor
+			f.Tags = append(existing.Tags, f.Tags...)
+		}
+		facets[anchor] = f
+	}
+	content = indexItemRegexp.ReplaceAllFunc(content, func(item []byte) []byte {
+		anchor := indexItemRegexp.FindSubmatch(item)[1]
+		f, ok := facets[string(anchor)]
+		if !ok {
+			return item
+		}
+		return []byte(fmt.Sprintf(`<li data-stability="%s" data-tags="%s"><a href="#%s">`, html.EscapeString(f.Stability), html.EscapeString(strings.Join(f.Tags, " ")), anchor))
+	})
+	var controls bytes.Buffer
+	controls.WriteString(indexHeading)
+	controls.WriteString(`<div class="kcl-facets" role="group" aria-label="Filter the schemas">` + "\n")
+	writeFacetSelect(&controls, "stability", "Stability", stabilities)
+	if len(tags) > 0 {
+		writeFacetSelect(&controls, "tags", "Tag", tags)
+	}
+	controls.WriteString("</div>\n")
+	content = bytes.Replace(content, []byte(indexHeading), controls.Bytes(), 1)
+	return append(content, facetsScript...)
+}
+
+// writeFacetSelect writes the select control of the facet with the values sorted
+func writeFacetSelect(buf *bytes.Buffer, facet string, label string, values map[string]bool) {
+	sorted := make([]string, 0, len(values))
+	for v := range values {
+		sorted = append(sorted, v)
+	}
+	sort.Strings(sorted)
+	fmt.Fprintf(buf, `<label>%s <select data-facet="%s">`, label, facet)
+	buf.WriteString(`<option value="">All</option>`)
+	for _, v := range sorted {
+		fmt.Fprintf(buf, `<option value="%s">%s</option>`, html.EscapeString(v), html.EscapeString(v))
+	}
+	buf.WriteString("</select></label>\n")
+}
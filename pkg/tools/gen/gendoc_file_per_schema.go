@@ -0,0 +1,306 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// docEnum is an enum declared as a type alias of the literal union type, such as `type Protocol = "TCP" | "UDP"`,
+// with the attributes of the schemas using it
+type docEnum struct {
+	Id      string
+	Members []string
+	UsedBy  []enumUse
+}
+
+// enumUse is an attribute of a schema whose type is the enum
+type enumUse struct {
+	SchemaId  string
+	Attribute string
+}
+
+// collectEnums collects the enums of the attributes of the schemas by enum id. The usages are sorted by the schema
+// ids and the attribute names
+func collectEnums(spec *SwaggerV2Spec) map[string]*docEnum {
+	enums := map[string]*docEnum{}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		for _, name := range getSortedKeys(schema.Properties) {
+			prop := schema.Properties[name]
+			if prop.KclExtensions == nil || prop.KclExtensions.XKclEnum == "" {
+				continue
+			}
+			e, ok := enums[prop.KclExtensions.XKclEnum]
+			if !ok {
+				e = &docEnum{Id: prop.KclExtensions.XKclEnum, Members: enumMembers(prop)}
+				enums[e.Id] = e
+			}
+			e.UsedBy = append(e.UsedBy, enumUse{SchemaId: id, Attribute: name})
+		}
+	}
+	return enums
+}
+
+// docFileName returns the page file name of the schema or the enum by its id, such as `models.Server.md`
+func (g *GenContext) docFileName(id string) string {
+	return fmt.Sprintf("%s.%s", id, g.Format)
+}
+
+// renderSchemaFiles writes the page of each schema and each enum, and the package page as the index of the pages
+func (g *GenContext) renderSchemaFiles(spec *SwaggerV2Spec, pkg *KclPackage, parentDir string) error {
+	g.docEnums = collectEnums(spec)
+	g.docFiles = map[string]string{}
+	for id := range spec.Definitions {
+		g.docFiles[id] = g.docFileName(id)
+	}
+	for id := range g.docEnums {
+		g.docFiles[id] = g.docFileName(id)
+	}
+	// the links to the pages are only rendered in the pages
+	defer func() {
+		g.docFiles = nil
+	}()
+	pages := map[string][]byte{}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		var buf bytes.Buffer
+		err := g.Template.ExecuteTemplate(&buf, "schemaDoc", []any{spec.Definitions[id], g.EscapeHtml})
+		if err != nil {
+			return fmt.Errorf("failed to render schema %s with template, err: %s", id, err)
+		}
+		frontMatter, err := g.annotationFrontMatter(spec.Definitions[id])
+		if err != nil {
+			return err
+		}
+		pages[g.docFiles[id]] = []byte(frontMatter + promoteHeadings(buf.String()) + docFooter)
+	}
+	for id, e := range g.docEnums {
+		pages[g.docFiles[id]] = g.enumPage(e)
+	}
+	var buf bytes.Buffer
+	err := g.Template.ExecuteTemplate(&buf, "packageDoc", struct {
+		EscapeHtml bool
+		Data       *KclPackage
+	}{
+		EscapeHtml: g.EscapeHtml,
+		Data:       pkg,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render package %s with template, err: %s", pkg.Name, err)
+	}
+	pkgName := pkg.Name
+	if pkgName == "" {
+		pkgName = "main"
+	}
+	pages[fmt.Sprintf("%s.%s", pkgName, g.Format)] = buf.Bytes()
+	for _, docFileName := range getSortedKeys(pages) {
+		content := pages[docFileName]
+		if g.Format == Html {
+			content = g.markdownToHtml(content)
+		}
+		// write content to file
+		err := g.writeOutput(parentDir, docFileName, g.withToolHeader(docFileName, content))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotationFrontMatter returns the front matter of the schema page with the values of the AnnotationsToFrontMatter
+// tags of the schema docstring, or an empty string if the schema declares none of the tags
+func (g *GenContext) annotationFrontMatter(schema *KclOpenAPIType) (string, error) {
+	if g.Format != Markdown {
+		return "", nil
+	}
+	var frontMatter yaml.MapSlice
+	for _, tag := range g.AnnotationsToFrontMatter {
+		switch values := annotationValues(schema.Description, tag); len(values) {
+		case 0:
+			continue
+		case 1:
+			frontMatter = append(frontMatter, yaml.MapItem{Key: tag, Value: values[0]})
+		default:
+			frontMatter = append(frontMatter, yaml.MapItem{Key: tag, Value: values})
+		}
+	}
+	if len(frontMatter) == 0 {
+		return "", nil
+	}
+	content, err := yaml.Marshal(frontMatter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("---\n%s---\n\n", content), nil
+}
+
+// docFooter is the footer of the generated markdown docs
+const docFooter = "<!-- Auto generated by kcl-doc tool, please do not edit. -->\n"
+
+// promoteHeadings raises the headings of the schema section by two levels for the schema page, such as `### Server`
+// to `# Server`. The lines of the fenced code blocks are kept
+func promoteHeadings(md string) string {
+	lines := strings.Split(md, "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced && strings.HasPrefix(line, "###") {
+			lines[i] = line[2:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// enumPage renders the page of the enum with its members and the attributes using it
+func (g *GenContext) enumPage(e *docEnum) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", schemaShortName(e.Id))
+	if i := strings.LastIndex(e.Id, "."); i >= 0 {
+		fmt.Fprintf(&buf, "Package: %s\n\n", e.Id[:i])
+	}
+	buf.WriteString("## Values\n\n")
+	for _, m := range e.Members {
+		fmt.Fprintf(&buf, "- `%s`\n", m)
+	}
+	buf.WriteString("\n## Used By\n\n")
+	for _, use := range e.UsedBy {
+		fmt.Fprintf(&buf, "- [%s](%s) `%s`\n", schemaShortName(use.SchemaId), g.docFiles[use.SchemaId], use.Attribute)
+	}
+	buf.WriteString(docFooter)
+	return buf.Bytes()
+}
+
+// docIndexEntry is an entry of the index of the pages
+type docIndexEntry struct {
+	Package string
+	Name    string
+	File    string
+}
+
+// fileIndexContent renders the index of the package linking the pages of the schemas grouped by the kinds, and the
+// pages of the enums under the "Enums" group
+func (g *GenContext) fileIndexContent(pkg *KclPackage) string {
+	var groups []string
+	for _, k := range indexKinds {
+		var entries []docIndexEntry
+		for _, schema := range pkg.allSchemas() {
+			if schema.kind() == k.Kind {
+				id := schema.schemaId()
+				entries = append(entries, docIndexEntry{Package: schema.KclExtensions.XKclModelType.Import.Package, Name: schema.KclExtensions.XKclModelType.Type, File: g.docFiles[id]})
+			}
+		}
+		if len(entries) > 0 {
+			groups = append(groups, fmt.Sprintf("### %s\n\n%s", k.Title, docIndexContent(entries)))
+		}
+	}
+	var enums []docIndexEntry
+	for id := range g.docEnums {
+		pkgName := ""
+		if i := strings.LastIndex(id, "."); i >= 0 {
+			pkgName = id[:i]
+		}
+		enums = append(enums, docIndexEntry{Package: pkgName, Name: schemaShortName(id), File: g.docFiles[id]})
+	}
+	if len(enums) > 0 {
+		groups = append(groups, fmt.Sprintf("### Enums\n\n%s", docIndexContent(enums)))
+	}
+	return strings.Join(groups, "\n")
+}
+
+// allSchemas returns the schemas of the package and its sub packages
+func (pkg *KclPackage) allSchemas() []*KclOpenAPIType {
+	schemas := append([]*KclOpenAPIType{}, pkg.SchemaList...)
+	for _, sub := range pkg.SubPackageList {
+		schemas = append(schemas, sub.allSchemas()...)
+	}
+	return schemas
+}
+
+// docIndexContent renders the entries nested in their packages like the package index: the entries of a package
+// are listed before its sub packages
+func docIndexContent(entries []docIndexEntry) string {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Package != entries[j].Package {
+			// the dot sorts before the identifier characters, so the packages sort before their sub packages
+			return entries[i].Package < entries[j].Package
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	var b strings.Builder
+	var parents []string
+	for _, e := range entries {
+		var path []string
+		if e.Package != "" {
+			path = strings.Split(e.Package, ".")
+		}
+		common := 0
+		for common < len(parents) && common < len(path) && parents[common] == path[common] {
+			common++
+		}
+		for i := common; i < len(path); i++ {
+			fmt.Fprintf(&b, "%s- %s\n", strings.Repeat("  ", i), path[i])
+		}
+		parents = path
+		fmt.Fprintf(&b, "%s- [%s](%s)\n", strings.Repeat("  ", len(path)), e.Name, e.File)
+	}
+	return b.String()
+}
+
+// linkDocFiles rewrites the links to the schema anchors in the rendered type name to the schema pages when writing
+// the pages, and renders the enum type as the link to the enum page. The anchors are resolved by the references of
+// the type, then by the schema names which are unique among the pages
+func (g *GenContext) linkDocFiles(tpe *KclOpenAPIType, typeName string) string {
+	if g.docFiles == nil {
+		return typeName
+	}
+	if tpe != nil && tpe.KclExtensions != nil {
+		if file, ok := g.docFiles[tpe.KclExtensions.XKclEnum]; ok {
+			return fmt.Sprintf("[%s](%s)", schemaShortName(tpe.KclExtensions.XKclEnum), file)
+		}
+	}
+	anchors := map[string]string{}
+	for id, file := range g.docFiles {
+		anchor := strings.ToLower(schemaShortName(id))
+		if _, ok := anchors[anchor]; ok {
+			// the schemas of the same name in different packages share the anchor
+			anchors[anchor] = ""
+		} else {
+			anchors[anchor] = file
+		}
+	}
+	for _, id := range tpe.schemaRefs() {
+		if file, ok := g.docFiles[id]; ok {
+			anchors[strings.ToLower(schemaShortName(id))] = file
+		}
+	}
+	return schemaLinkRegexp.ReplaceAllStringFunc(typeName, func(link string) string {
+		m := schemaLinkRegexp.FindStringSubmatch(link)
+		if file := anchors[m[2]]; file != "" {
+			return fmt.Sprintf("[%s](%s)", m[1], file)
+		}
+		return link
+	})
+}
+
+// schemaRefs returns the ids of the schemas referenced by the type, its items, values and union members
+func (tpe *KclOpenAPIType) schemaRefs() []string {
+	if tpe == nil {
+		return nil
+	}
+	if tpe.Ref != "" {
+		return []string{Ref2SchemaId(tpe.Ref)}
+	}
+	refs := append(tpe.Items.schemaRefs(), tpe.AdditionalProperties.schemaRefs()...)
+	if tpe.KclExtensions != nil {
+		for _, t := range tpe.KclExtensions.XKclUnionTypes {
+			refs = append(refs, t.schemaRefs()...)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,79 @@
+package gen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// schemaLinkRegexp matches the links to the schema anchors in the rendered type names, such as `[Server](#server)`
+var schemaLinkRegexp = regexp.MustCompile(`\[([^\]]+)\]\(#([^)]+)\)`)
+
+// schemaFilter filters the schemas by matching the qualified schema names such as `models.Server` against the
+// include and exclude patterns
+type schemaFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// compileSchemaFilter compiles the IncludePattern and ExcludePattern, and returns nil if neither is set
+func (g *GenContext) compileSchemaFilter() (*schemaFilter, error) {
+	if g.IncludePattern == "" && g.ExcludePattern == "" {
+		return nil, nil
+	}
+	f := &schemaFilter{}
+	var err error
+	if g.IncludePattern != "" {
+		if f.include, err = regexp.Compile(g.IncludePattern); err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %s", g.IncludePattern, err)
+		}
+	}
+	if g.ExcludePattern != "" {
+		if f.exclude, err = regexp.Compile(g.ExcludePattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %s", g.ExcludePattern, err)
+		}
+	}
+	return f, nil
+}
+
+// matches checks if the schema is rendered: it must match the include pattern if set, and must not match the
+// exclude pattern
+func (f *schemaFilter) matches(id string) bool {
+	if f.include != nil && !f.include.MatchString(id) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.MatchString(id)
+}
+
+// filterSpec returns a copy of the spec with only the schemas to render, and the anchors of the filtered out schemas
+// which are not shared with any rendered schema
+func (f *schemaFilter) filterSpec(spec *SwaggerV2Spec) (*SwaggerV2Spec, map[string]bool) {
+	filtered := *spec
+	filtered.Definitions = make(map[string]*KclOpenAPIType, len(spec.Definitions))
+	excluded := map[string]bool{}
+	for id, schema := range spec.Definitions {
+		if f.matches(id) {
+			filtered.Definitions[id] = schema
+		} else {
+			excluded[strings.ToLower(schemaShortName(id))] = true
+		}
+	}
+	for id := range filtered.Definitions {
+		delete(excluded, strings.ToLower(schemaShortName(id)))
+	}
+	return &filtered, excluded
+}
+
+// unlinkExcludedSchemas replaces the links to the filtered out schemas in the rendered type name with the plain text
+func (g *GenContext) unlinkExcludedSchemas(typeName string) string {
+	if len(g.excludedAnchors) == 0 {
+		return typeName
+	}
+	return schemaLinkRegexp.ReplaceAllStringFunc(typeName, func(link string) string {
+		m := schemaLinkRegexp.FindStringSubmatch(link)
+		if g.excludedAnchors[m[2]] {
+			return m[1]
+		}
+		return link
+	})
+}
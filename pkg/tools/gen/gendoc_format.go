@@ -0,0 +1,44 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatExamples are the example values of the known string formats declared with the `@format <format>` tag, such
+// as `@format date-time`
+var formatExamples = map[string]string{
+	"date":      "2024-01-02",
+	"date-time": "2024-01-02T15:04:05Z",
+	"duration":  "PT1H30M",
+	"email":     "user@example.com",
+	"uri":       "https://example.com/path",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// stringFormat returns the format of the attribute declared with the `@format` tag in lower case
+func (tpe *KclOpenAPIType) stringFormat() string {
+	if values := annotationValues(tpe.Description, "format"); len(values) > 0 {
+		return strings.ToLower(values[0])
+	}
+	return ""
+}
+
+// formatExample returns the example value of the format of the attribute, or false if the format is unknown
+func (tpe *KclOpenAPIType) formatExample() (string, bool) {
+	example, ok := formatExamples[tpe.stringFormat()]
+	return example, ok
+}
+
+// formatHint renders the format of the attribute with the example value of the known formats, such as
+// "format: date-time, e.g. `2024-01-02T15:04:05Z`". The unknown formats are rendered without an example
+func formatHint(tpe *KclOpenAPIType) string {
+	format := tpe.stringFormat()
+	if format == "" {
+		return ""
+	}
+	if example, ok := tpe.formatExample(); ok {
+		return fmt.Sprintf("format: %s, e.g. `%s`", format, example)
+	}
+	return fmt.Sprintf("format: %s", format)
+}
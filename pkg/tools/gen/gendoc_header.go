@@ -0,0 +1,70 @@
+package gen
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"kcl-lang.io/kcl-go/scripts"
+)
+
+// kclGoModule is the module path of kcl-go, whose version is read from the build info
+const kclGoModule = "kcl-lang.io/kcl-go"
+
+// timeNow returns the generation time in the header, which is replaced in the tests
+var timeNow = time.Now
+
+// toolVersion returns the version of kcl-go in the build info of the binary, which is `(devel)` for the local builds
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	if info.Main.Path == kclGoModule {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != kclGoModule {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "(devel)"
+}
+
+// toolHeader returns the header line of the generated files with the kcl-go version, the KCL version and the
+// generation time. The time is omitted in the deterministic mode so the repeated generations are identical
+func (g *GenContext) toolHeader() string {
+	header := fmt.Sprintf("Generated by kcl-go %s with KCL %s", toolVersion(), scripts.KclvmAbiVersion)
+	if !g.Deterministic {
+		header += fmt.Sprintf(" at %s", timeNow().UTC().Format(time.RFC3339))
+	}
+	return header
+}
+
+// withToolHeader prepends the header to the content of the generated file as a comment of its format when
+// EmbedToolVersion is set. The JSON files which can not carry comments are unchanged
+func (g *GenContext) withToolHeader(fileName string, content []byte) []byte {
+	if !g.EmbedToolVersion {
+		return content
+	}
+	var line string
+	switch filepath.Ext(fileName) {
+	case ".md":
+		line = fmt.Sprintf("<!-- %s -->\n", g.toolHeader())
+	case ".html":
+		line = fmt.Sprintf("<meta name=\"generator\" content=\"%s\">\n", html.EscapeString(g.toolHeader()))
+	case ".libsonnet", ".cel":
+		line = fmt.Sprintf("// %s\n", g.toolHeader())
+	case ".k":
+		line = fmt.Sprintf("# %s\n", g.toolHeader())
+	default:
+		return content
+	}
+	return append([]byte(line), content...)
+}
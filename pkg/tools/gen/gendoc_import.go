@@ -0,0 +1,78 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// collectImportStatements computes the import statement to reference each schema in another package, which is
+// rendered above the schema examples. The alias is the one most used to import the package in the sources, if it
+// differs from the last part of the package path. The schemas in the root package need no import.
+func (g *GenContext) collectImportStatements(spec *SwaggerV2Spec) {
+	g.importStatements = map[string]string{}
+	aliases := map[string]map[string]int{}
+	for _, schema := range spec.Definitions {
+		for _, prop := range schema.Properties {
+			for _, ref := range importAliasRefs(prop) {
+				pkg := schemaPackage(Ref2SchemaId(ref.Ref))
+				if aliases[pkg] == nil {
+					aliases[pkg] = map[string]int{}
+				}
+				aliases[pkg][ref.KclExtensions.XKclImportAlias]++
+			}
+		}
+	}
+	for id, schema := range spec.Definitions {
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		pkg := schema.KclExtensions.XKclModelType.Import.Package
+		if pkg == "" {
+			continue
+		}
+		statement := fmt.Sprintf("import %s", pkg)
+		if alias := mostUsedAlias(aliases[pkg]); alias != "" {
+			statement = fmt.Sprintf("%s as %s", statement, alias)
+		}
+		g.importStatements[id] = statement
+	}
+}
+
+// importAliasRefs returns the references to the schemas in the type which are written with an import alias
+func importAliasRefs(tpe *KclOpenAPIType) []*KclOpenAPIType {
+	if tpe == nil {
+		return nil
+	}
+	if tpe.Ref != "" {
+		if tpe.KclExtensions != nil && tpe.KclExtensions.XKclImportAlias != "" {
+			return []*KclOpenAPIType{tpe}
+		}
+		return nil
+	}
+	refs := append(importAliasRefs(tpe.Items), importAliasRefs(tpe.AdditionalProperties)...)
+	if tpe.KclExtensions != nil {
+		for _, t := range tpe.KclExtensions.XKclUnionTypes {
+			refs = append(refs, importAliasRefs(t)...)
+		}
+	}
+	return refs
+}
+
+// schemaPackage returns the package path of the schema id, which is empty for the root package
+func schemaPackage(id string) string {
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		return id[:i]
+	}
+	return ""
+}
+
+// mostUsedAlias returns the alias used the most times, and the first one in order on a tie
+func mostUsedAlias(counts map[string]int) string {
+	var alias string
+	for _, a := range getSortedKeys(counts) {
+		if counts[a] > counts[alias] {
+			alias = a
+		}
+	}
+	return alias
+}
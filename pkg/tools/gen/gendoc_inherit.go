@@ -0,0 +1,165 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// inheritedAttributeGroup is the attributes of a schema inherited from its base schema
+type inheritedAttributeGroup struct {
+	Base    string   // short name of the base schema
+	Names   []string // sorted names of the inherited attributes
+	Details bool     // whether to collapse the attributes in a <details> element instead of a separate section
+}
+
+// collectInheritedAttributes resolves the attributes each schema inherits from its base schema when
+// CollapseInherited is set and the attributes are not grouped by owner. The schema properties exported by KCL already contain the inherited attributes,
+// so the inherited ones are those also defined by the base schema
+func (g *GenContext) collectInheritedAttributes(spec *SwaggerV2Spec) {
+	g.inheritedAttributes = map[string]*inheritedAttributeGroup{}
+	if !g.CollapseInherited || g.AttributeStyle == ByOwner {
+		return
+	}
+	for id, schema := range spec.Definitions {
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclBaseSchema == "" {
+			continue
+		}
+		base, ok := spec.Definitions[Ref2SchemaId(schema.KclExtensions.XKclBaseSchema)]
+		if !ok {
+			continue
+		}
+		var names []string
+		for name := range schema.Properties {
+			if _, ok := base.Properties[name]; ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		g.inheritedAttributes[id] = &inheritedAttributeGroup{
+			Base:    base.KclExtensions.XKclModelType.Type,
+			Names:   names,
+			Details: g.Format == Html,
+		}
+	}
+}
+
+// inheritedAttributeGroup returns the inherited attributes of the schema, or nil if there are none to collapse
+func (g *GenContext) inheritedAttributeGroup(schema *KclOpenAPIType) *inheritedAttributeGroup {
+	if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+		return nil
+	}
+	return g.inheritedAttributes[schema.schemaId()]
+}
+
+// checkInheritanceConflicts checks the attributes of each schema against the same attributes of its base schema.
+// The attributes whose types are not narrowed from the base types are reported as warnings with both definitions, or
+// fail the generation if ErrorOnInheritanceConflict is set
+func (g *GenContext) checkInheritanceConflicts(spec *SwaggerV2Spec) error {
+	var conflicts []string
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclBaseSchema == "" {
+			continue
+		}
+		baseId := Ref2SchemaId(schema.KclExtensions.XKclBaseSchema)
+		base, ok := spec.Definitions[baseId]
+		if !ok {
+			continue
+		}
+		for _, name := range getSortedKeys(schema.Properties) {
+			baseProp, ok := base.Properties[name]
+			if !ok || isNarrowedType(spec, schema.Properties[name], baseProp) {
+				continue
+			}
+			conflicts = append(conflicts, fmt.Sprintf("the attribute %s.%s: %s conflicts with %s.%s: %s of the base schema",
+				id, name, authoredType(schema.Properties[name]), baseId, name, authoredType(baseProp)))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if g.ErrorOnInheritanceConflict {
+		return fmt.Errorf("found attributes conflicting with the base schemas:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	for _, c := range conflicts {
+		g.warnf("%s", c)
+	}
+	return nil
+}
+
+// authoredType returns the type expression of the attribute as authored, or the type label
+func authoredType(tpe *KclOpenAPIType) string {
+	if tpe.KclExtensions != nil && tpe.KclExtensions.XKclTypeExpr != "" {
+		return tpe.KclExtensions.XKclTypeExpr
+	}
+	label := tpe.GetKclTypeName(false, false, false)
+	if tpe.Nullable {
+		label += " | None"
+	}
+	return label
+}
+
+// isNarrowedType reports whether the derived type accepts only the values of the base type, such as the literal
+// types of the base type, the members of the base union type, the schemas derived from the base schema and int
+// for float
+func isNarrowedType(spec *SwaggerV2Spec, derived *KclOpenAPIType, base *KclOpenAPIType) bool {
+	if base.isAnyType() {
+		return true
+	}
+	if derived.Nullable && !base.Nullable {
+		return false
+	}
+	if derived.KclExtensions != nil && len(derived.KclExtensions.XKclUnionTypes) > 0 {
+		for _, member := range derived.KclExtensions.XKclUnionTypes {
+			if !isNarrowedType(spec, member, base) {
+				return false
+			}
+		}
+		return true
+	}
+	if base.KclExtensions != nil && len(base.KclExtensions.XKclUnionTypes) > 0 {
+		for _, member := range base.KclExtensions.XKclUnionTypes {
+			if isNarrowedType(spec, derived, member) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case derived.Ref != "" || base.Ref != "":
+		if derived.Ref == "" || base.Ref == "" {
+			return false
+		}
+		return isDerivedSchema(spec, Ref2SchemaId(derived.Ref), Ref2SchemaId(base.Ref))
+	case base.ReadOnly && len(base.Enum) > 0:
+		return derived.ReadOnly && len(derived.Enum) > 0 && derived.Enum[0] == base.Enum[0]
+	case derived.Type == Array && base.Type == Array:
+		return isNarrowedType(spec, derived.Items, base.Items)
+	case derived.Type == Object && base.Type == Object && derived.AdditionalProperties != nil && base.AdditionalProperties != nil:
+		return isNarrowedType(spec, derived.AdditionalProperties, base.AdditionalProperties)
+	case derived.Type == Integer && derived.Format == Int64 && base.Type == Number:
+		return true
+	}
+	return derived.Type == base.Type && derived.Format == base.Format && !derived.isAnyType()
+}
+
+// isDerivedSchema reports whether the schema is the base schema or is derived from it
+func isDerivedSchema(spec *SwaggerV2Spec, id string, baseId string) bool {
+	seen := map[string]bool{}
+	for id != "" && !seen[id] {
+		if id == baseId {
+			return true
+		}
+		seen[id] = true
+		schema, ok := spec.Definitions[id]
+		if !ok || schema.KclExtensions == nil || schema.KclExtensions.XKclBaseSchema == "" {
+			return false
+		}
+		id = Ref2SchemaId(schema.KclExtensions.XKclBaseSchema)
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schemaKind is the kind of the plain schemas
+const schemaKind = "schema"
+
+// indexKinds are the kinds grouped in the index, in order, with the group titles
+var indexKinds = []struct {
+	Kind  string
+	Title string
+}{
+	{schemaKind, "Schemas"},
+	{MixinKind, "Mixins"},
+	{ProtocolKind, "Protocols"},
+}
+
+// kind returns the kind of the declaration: schema, mixin or protocol
+func (tpe *KclOpenAPIType) kind() string {
+	if tpe.KclExtensions == nil || tpe.KclExtensions.XKclKind == "" {
+		return schemaKind
+	}
+	return tpe.KclExtensions.XKclKind
+}
+
+// hasKind checks if the package or any of its sub packages declares the kind
+func (pkg *KclPackage) hasKind(kind string) bool {
+	for _, schema := range pkg.SchemaList {
+		if schema.kind() == kind {
+			return true
+		}
+	}
+	for _, sub := range pkg.SubPackageList {
+		if sub.hasKind(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// getKindIndexContent renders the index of the declarations of the kind, skipping the packages without any
+func (pkg *KclPackage) getKindIndexContent(kind string, level int, indentation string) string {
+	var content string
+	for _, schema := range pkg.SchemaList {
+		if schema.kind() == kind {
+			content += schema.getSchemaIndexContent(level, indentation)
+		}
+	}
+	for _, sub := range pkg.SubPackageList {
+		if sub.hasKind(kind) {
+			content += fmt.Sprintf("%s- %s\n%s", strings.Repeat(indentation, level), sub.Name, sub.getKindIndexContent(kind, level+1, indentation))
+		}
+	}
+	return content
+}
+
+// indexContent renders the index of the package. If the package declares any mixin or protocol, the index is grouped
+// by the kinds under the group titles
+func indexContent(pkg *KclPackage) string {
+	if !pkg.hasKind(MixinKind) && !pkg.hasKind(ProtocolKind) {
+		return pkg.getIndexContent(0, "  ")
+	}
+	var groups []string
+	for _, k := range indexKinds {
+		if pkg.hasKind(k.Kind) {
+			groups = append(groups, fmt.Sprintf("### %s\n\n%s", k.Title, pkg.getKindIndexContent(k.Kind, 0, "  ")))
+		}
+	}
+	return strings.Join(groups, "\n")
+}
+
+// protocolSignature renders the attribute declarations of the protocol, which the schemas mixed with the mixins for
+// the protocol must provide
+func protocolSignature(tpe *KclOpenAPIType) string {
+	if tpe.kind() != ProtocolKind {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("protocol %s:", tpe.KclExtensions.XKclModelType.Type)}
+	for _, name := range getSortedKeys(tpe.Properties) {
+		optional := "?"
+		if containsString(tpe.Required, name) {
+			optional = ""
+		}
+		lines = append(lines, fmt.Sprintf("    %s%s: %s", name, optional, tpe.Properties[name].GetKclTypeName(false, false, false)))
+	}
+	return strings.Join(lines, "\n")
+}
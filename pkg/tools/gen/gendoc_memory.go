@@ -0,0 +1,138 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kcl-lang.io/kcl-go/pkg/kcl"
+)
+
+// memoryOutputs is the generated files rendered in memory by the slash-separated paths relative to the root target
+type memoryOutputs struct {
+	root  string
+	files map[string][]byte
+}
+
+// writeOutput writes the generated file into the directory, or records it in memory when rendering from the sources
+func (g *GenContext) writeOutput(dir string, name string, content []byte) error {
+	if g.memoryOutputs != nil {
+		rel, err := filepath.Rel(g.memoryOutputs.root, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		g.memoryOutputs.files[filepath.ToSlash(rel)] = content
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	err := os.WriteFile(filepath.Join(dir, name), content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s in %s: %v", name, dir, err)
+	}
+	return nil
+}
+
+// RenderFromSource renders the docs of the package of the virtual files, which are the KCL sources by the
+// slash-separated paths in the same directory, without reading or writing the disk. The source file links reference
+// the virtual paths, and the generated files are returned by the slash-separated paths relative to the target
+func (g *GenContext) RenderFromSource(files map[string]string) (map[string][]byte, error) {
+	dir, err := virtualPackageDir(files)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := exportSwaggerV2SpecFromSource(files, g.logger())
+	if err != nil {
+		return nil, err
+	}
+	return g.renderInMemory(spec, dir, files)
+}
+
+// renderInMemory renders the spec exported from the virtual files in the directory into memory
+func (g *GenContext) renderInMemory(spec *SwaggerV2Spec, dir string, files map[string]string) (map[string][]byte, error) {
+	if g.EmitPDF {
+		return nil, fmt.Errorf("the PDF output is not supported when rendering from the sources")
+	}
+	c, err := g.clone()
+	if err != nil {
+		return nil, err
+	}
+	c.memoryOutputs = &memoryOutputs{root: c.Target, files: map[string][]byte{}}
+	c.virtualSources = map[string]string{}
+	for p, source := range files {
+		c.virtualSources[path.Base(p)] = source
+	}
+	if dir != "." {
+		resolve := g.SourcePathResolver
+		c.SourcePathResolver = func(original string) string {
+			p := path.Join(dir, original)
+			if resolve != nil {
+				return resolve(p)
+			}
+			return p
+		}
+	}
+	if err := c.renderLocales(spec); err != nil {
+		return nil, fmt.Errorf("render doc failed: %s", err)
+	}
+	g.Warnings = append(g.Warnings, c.Warnings...)
+	g.UnresolvedLinks = append(g.UnresolvedLinks, c.UnresolvedLinks...)
+	return c.memoryOutputs.files, nil
+}
+
+// virtualPackageDir returns the directory of the virtual files, which must be the .k files in the same directory
+func virtualPackageDir(files map[string]string) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("no KCL source to render")
+	}
+	dir := ""
+	for p := range files {
+		if path.Ext(p) != ".k" {
+			return "", fmt.Errorf("the virtual file %s is not a KCL source", p)
+		}
+		d := path.Dir(path.Clean(strings.ReplaceAll(p, "\\", "/")))
+		if dir != "" && d != dir {
+			return "", fmt.Errorf("the virtual files must be in the same directory, found %s and %s", dir, d)
+		}
+		dir = d
+	}
+	return dir, nil
+}
+
+// exportSwaggerV2SpecFromSource exports the swagger v2 spec of the package of the virtual files
+func exportSwaggerV2SpecFromSource(files map[string]string, logger Logger) (*SwaggerV2Spec, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	codes := make([]string, len(paths))
+	for i, p := range paths {
+		codes[i] = files[p]
+	}
+	types, err := kcl.GetFullSchemaType(paths, "", kcl.WithCode(codes...))
+	if err != nil {
+		return nil, err
+	}
+	readSource := func(filename string) string {
+		return files[filepath.ToSlash(filename)]
+	}
+	spec := &SwaggerV2Spec{
+		Swagger:     "2.0",
+		Definitions: make(map[string]*KclOpenAPIType),
+		Paths:       map[string]interface{}{},
+	}
+	for _, t := range types {
+		if t.Type != typSchema {
+			continue
+		}
+		id := SchemaId(".", t)
+		spec.Definitions[id] = getKclOpenAPIType(".", t, false, readSource)
+		logger.Info("exporting openAPI spec from schema", "schema", id)
+	}
+	return spec, nil
+}
@@ -0,0 +1,111 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const (
+	metricsFile = "metrics.json"
+	// maxMetricsDepth is the cap of the nesting depth when following the schema references
+	maxMetricsDepth = 32
+)
+
+// schemaMetrics is the size and complexity metrics of a schema
+type schemaMetrics struct {
+	Schema      string `json:"schema"`
+	Attributes  int    `json:"attributes"`  // number of the attributes
+	Depth       int    `json:"depth"`       // max nesting depth following the schema references
+	Constraints int    `json:"constraints"` // number of the check expressions
+	References  int    `json:"references"`  // number of the references to other schemas
+	Complexity  int    `json:"complexity"`  // sum of the metrics above
+}
+
+// collectSchemaMetrics computes the metrics of all the schemas when IncludeMetrics is set
+func (g *GenContext) collectSchemaMetrics(spec *SwaggerV2Spec) {
+	g.schemaMetrics = map[string]*schemaMetrics{}
+	if !g.IncludeMetrics {
+		return
+	}
+	for id, schema := range spec.Definitions {
+		m := &schemaMetrics{
+			Schema:     id,
+			Attributes: len(schema.Properties),
+			Depth:      schemaDepth(spec, schema, map[string]bool{id: true}, 1),
+		}
+		if schema.KclExtensions != nil {
+			m.Constraints = len(schema.KclExtensions.XKclChecks)
+		}
+		for _, prop := range schema.Properties {
+			m.References += len(typeRefs(prop))
+		}
+		m.Complexity = m.Attributes + m.Depth + m.Constraints + m.References
+		g.schemaMetrics[id] = m
+	}
+}
+
+// schemaDepth returns the max nesting depth of the schema. The references are followed up to maxMetricsDepth, and
+// the schemas already on the path are not followed again to stop on the cycles
+func schemaDepth(spec *SwaggerV2Spec, schema *KclOpenAPIType, path map[string]bool, depth int) int {
+	max := depth
+	if depth >= maxMetricsDepth {
+		return max
+	}
+	for _, prop := range schema.Properties {
+		for _, ref := range typeRefs(prop) {
+			id := Ref2SchemaId(ref)
+			nested, ok := spec.Definitions[id]
+			if !ok || path[id] {
+				continue
+			}
+			path[id] = true
+			if d := schemaDepth(spec, nested, path, depth+1); d > max {
+				max = d
+			}
+			delete(path, id)
+		}
+	}
+	return max
+}
+
+// typeRefs returns the schema references in the type, including the ones in the list items, dict values and union types
+func typeRefs(tpe *KclOpenAPIType) []string {
+	if tpe == nil {
+		return nil
+	}
+	if tpe.Ref != "" {
+		return []string{tpe.Ref}
+	}
+	refs := append(typeRefs(tpe.Items), typeRefs(tpe.AdditionalProperties)...)
+	if tpe.KclExtensions != nil {
+		for _, t := range tpe.KclExtensions.XKclUnionTypes {
+			refs = append(refs, typeRefs(t)...)
+		}
+	}
+	return refs
+}
+
+// metricsLine renders the metrics of the schema in one line
+func (m *schemaMetrics) metricsLine() string {
+	return fmt.Sprintf("Attributes: %d · Max depth: %d · Constraints: %d · References: %d", m.Attributes, m.Depth, m.Constraints, m.References)
+}
+
+// renderMetrics writes the metrics of all the schemas sorted by the complexity descending
+func (g *GenContext) renderMetrics() error {
+	metrics := make([]*schemaMetrics, 0, len(g.schemaMetrics))
+	for _, m := range g.schemaMetrics {
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Complexity != metrics[j].Complexity {
+			return metrics[i].Complexity > metrics[j].Complexity
+		}
+		return metrics[i].Schema < metrics[j].Schema
+	})
+	content, err := json.MarshalIndent(metrics, "", "    ")
+	if err != nil {
+		return err
+	}
+	return g.writeOutput(g.Target, metricsFile, content)
+}
@@ -0,0 +1,69 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkOrphanSchemas reports the schemas which no schema references and which are not the Roots, when ErrorOnOrphans
+// is set or the Roots are configured. The orphans are reported as warnings, or fail the generation if ErrorOnOrphans is
+// set.
+func (g *GenContext) checkOrphanSchemas(spec *SwaggerV2Spec) error {
+	if !g.ErrorOnOrphans && len(g.Roots) == 0 {
+		return nil
+	}
+	orphans, err := orphanSchemas(spec, g.Roots)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	if g.ErrorOnOrphans {
+		return fmt.Errorf("found schemas which are not referenced by any schema or the roots: %s", strings.Join(orphans, ", "))
+	}
+	for _, id := range orphans {
+		g.warnf("schema %s is not referenced by any schema or the roots", id)
+	}
+	return nil
+}
+
+// orphanSchemas returns the sorted ids of the schemas without incoming edges in the reference graph, whose edges are
+// the attribute types, the base schemas and the mixins, except the roots. The references of a schema to itself are
+// not incoming edges.
+func orphanSchemas(spec *SwaggerV2Spec, roots []string) ([]string, error) {
+	entries := map[string]bool{}
+	for _, id := range roots {
+		if _, ok := spec.Definitions[id]; !ok {
+			return nil, fmt.Errorf("unknown root schema %s", id)
+		}
+		entries[id] = true
+	}
+	referenced := map[string]bool{}
+	for id, schema := range spec.Definitions {
+		var refs []string
+		for _, prop := range schema.Properties {
+			refs = append(refs, typeRefs(prop)...)
+		}
+		if schema.KclExtensions != nil {
+			if schema.KclExtensions.XKclBaseSchema != "" {
+				refs = append(refs, schema.KclExtensions.XKclBaseSchema)
+			}
+			refs = append(refs, schema.KclExtensions.XKclMixins...)
+		}
+		for _, ref := range refs {
+			if target := Ref2SchemaId(ref); target != id {
+				referenced[target] = true
+			}
+		}
+	}
+	var orphans []string
+	for id := range spec.Definitions {
+		if !referenced[id] && !entries[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
@@ -0,0 +1,104 @@
+package gen
+
+// AttributeStyle is the layout of the attributes of the schemas in the docs
+type AttributeStyle string
+
+const (
+	// Flat renders all the attributes of a schema in one table sorted by name
+	Flat AttributeStyle = ""
+	// ByOwner renders the attributes under the `From <Owner>` subheadings of the schemas declaring them: the direct
+	// declarations first, then each mixin and base schema. The attributes of each owner are in the source order
+	ByOwner AttributeStyle = "owner"
+)
+
+// attributeOwnerGroup is the attributes of a schema declared by an owner, which is the schema itself, one of its
+// mixins or one of its base schemas
+type attributeOwnerGroup struct {
+	Owner string   // short name of the owner
+	Names []string // names of the attributes in the source order of the owner
+}
+
+// collectAttributeOwners resolves the owner groups of the attributes of each schema when the AttributeStyle is ByOwner
+func (g *GenContext) collectAttributeOwners(spec *SwaggerV2Spec) {
+	g.attributeOwners = map[string][]*attributeOwnerGroup{}
+	if g.AttributeStyle != ByOwner {
+		return
+	}
+	for id, schema := range spec.Definitions {
+		if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+			continue
+		}
+		g.attributeOwners[id] = attributeOwnerGroups(spec, schema)
+	}
+}
+
+// attributeOwnerGroups groups the attributes of the schema by their owners. The owners are the schema itself, its
+// mixins in the declaration order, then its base schema with the mixins and the base schema of the base schema, and
+// each attribute belongs to the first owner declaring it. The attributes without the known owners are the direct
+// declarations. The schemas declaring all their attributes have no groups
+func attributeOwnerGroups(spec *SwaggerV2Spec, schema *KclOpenAPIType) []*attributeOwnerGroup {
+	claimed := map[string]bool{}
+	claim := func(owner *KclOpenAPIType) *attributeOwnerGroup {
+		group := &attributeOwnerGroup{Owner: owner.KclExtensions.XKclModelType.Type}
+		names := owner.KclExtensions.XKclAttributeOrder
+		if len(names) == 0 && owner != schema {
+			names = getSortedKeys(owner.Properties)
+		}
+		for _, name := range names {
+			if _, ok := schema.Properties[name]; ok && !claimed[name] {
+				claimed[name] = true
+				group.Names = append(group.Names, name)
+			}
+		}
+		return group
+	}
+	direct := claim(schema)
+	groups := []*attributeOwnerGroup{direct}
+	seen := map[string]bool{schema.schemaId(): true}
+	var owners func(t *KclOpenAPIType)
+	owners = func(t *KclOpenAPIType) {
+		refs := append([]string{}, t.KclExtensions.XKclMixins...)
+		if t.KclExtensions.XKclBaseSchema != "" {
+			refs = append(refs, t.KclExtensions.XKclBaseSchema)
+		}
+		for i, ref := range refs {
+			id := Ref2SchemaId(ref)
+			owner, ok := spec.Definitions[id]
+			if !ok || seen[id] || owner.KclExtensions == nil || owner.KclExtensions.XKclModelType == nil {
+				continue
+			}
+			seen[id] = true
+			groups = append(groups, claim(owner))
+			if i == len(refs)-1 && t.KclExtensions.XKclBaseSchema != "" {
+				// the mixins and the base schema of the base schema
+				owners(owner)
+			}
+		}
+	}
+	owners(schema)
+	for _, name := range getSortedKeys(schema.Properties) {
+		if !claimed[name] {
+			direct.Names = append(direct.Names, name)
+		}
+	}
+	var result []*attributeOwnerGroup
+	for _, group := range groups {
+		if len(group.Names) > 0 {
+			result = append(result, group)
+		}
+	}
+	if len(result) == 1 && result[0] == direct {
+		// the schema declares all its attributes, which are not grouped
+		return nil
+	}
+	return result
+}
+
+// attributeOwnerGroupsOf returns the owner groups of the attributes of the schema, or nil if the attributes are not
+// grouped by owner
+func (g *GenContext) attributeOwnerGroupsOf(schema *KclOpenAPIType) []*attributeOwnerGroup {
+	if schema.KclExtensions == nil || schema.KclExtensions.XKclModelType == nil {
+		return nil
+	}
+	return g.attributeOwners[schema.schemaId()]
+}
@@ -0,0 +1,73 @@
+package gen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// packageDeprecation is the deprecation of a package declared with the `@deprecated [note]` tag in the package
+// docstring, and its successor declared with the `@deprecated-use <pkg>` tag
+type packageDeprecation struct {
+	Note      string `json:"note,omitempty"`
+	Successor string `json:"successor,omitempty"`
+}
+
+// parsePackageDeprecation returns the deprecation of the package docstring, or nil if the package is not deprecated
+func parsePackageDeprecation(doc string) *packageDeprecation {
+	_, annotations := parseDocAnnotations(doc)
+	var d *packageDeprecation
+	for _, a := range annotations {
+		if a.Name != "deprecated" && a.Name != "deprecated-use" {
+			continue
+		}
+		if d == nil {
+			d = &packageDeprecation{}
+		}
+		if a.Name == "deprecated" {
+			d.Note = a.Value
+		} else {
+			d.Successor = a.Value
+		}
+	}
+	return d
+}
+
+// banner renders the deprecation banner of the package. The successor links to its doc if the link is known
+func (d *packageDeprecation) banner(successorLink string) string {
+	text := "**Deprecated**: this package is deprecated."
+	if d.Note != "" {
+		text = fmt.Sprintf("**Deprecated**: %s", d.Note)
+	}
+	if d.Successor != "" {
+		successor := fmt.Sprintf("`%s`", d.Successor)
+		if successorLink != "" {
+			successor = fmt.Sprintf("[%s](%s)", d.Successor, successorLink)
+		}
+		text += fmt.Sprintf(" Use %s instead.", successor)
+	}
+	return text
+}
+
+// packageDeprecationBanner renders the deprecation banner of the root package. In a workspace the successor links to
+// the doc of the workspace module of the name, relative to the doc of the package
+func (g *GenContext) packageDeprecationBanner(pkg *KclPackage) string {
+	d := parsePackageDeprecation(pkg.Description)
+	if d == nil {
+		return ""
+	}
+	var link string
+	if target, ok := g.packageDocLinks[d.Successor]; ok {
+		link = relativeDocLink(g.packageDocDir, target)
+	}
+	return d.banner(link)
+}
+
+// relativeDocLink returns the link to the slash-separated doc path from the directory, both relative to the
+// workspace target
+func relativeDocLink(dir string, target string) string {
+	if dir == "." || dir == "" {
+		return target
+	}
+	return strings.Repeat("../", len(strings.Split(dir, "/"))) + path.Clean(target)
+}
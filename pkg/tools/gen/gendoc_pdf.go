@@ -0,0 +1,113 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkHtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// defaultPDFConverter is the HTML-to-PDF converter looked up in the PATH if PDFConverter is not set
+const defaultPDFConverter = "wkhtmltopdf"
+
+// pdfStyle breaks the pages before each schema, which is rendered as the h3 heading
+const pdfStyle = `body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px; }
+.cover { text-align: center; page-break-after: always; }
+h3 { page-break-before: always; }
+`
+
+// pdfConverter returns the path to the HTML-to-PDF converter
+func (g *GenContext) pdfConverter() (string, error) {
+	if g.PDFConverter != "" {
+		if _, err := os.Stat(g.PDFConverter); err != nil {
+			return "", fmt.Errorf("the PDF converter %s is not available: %s", g.PDFConverter, err)
+		}
+		return g.PDFConverter, nil
+	}
+	converter, err := exec.LookPath(defaultPDFConverter)
+	if err != nil {
+		return "", fmt.Errorf("no PDF converter is available: install %s or set the PDF converter path", defaultPDFConverter)
+	}
+	return converter, nil
+}
+
+// renderPDF renders the package doc to a single PDF with a cover page, the index as the table of contents, and the
+// page breaks between the schemas. The schema headings have the ids which the index links to, and are used as the
+// bookmarks by the converter. The converter is invoked as `<converter> <input.html> <output.pdf>`.
+func (g *GenContext) renderPDF(spec *SwaggerV2Spec) error {
+	converter, err := g.pdfConverter()
+	if err != nil {
+		return err
+	}
+	pkg := spec.toKclPackage()
+	pkg.sortSchemasAndPkgs()
+	pkgName := pkg.Name
+	if pkg.Name == "" {
+		pkgName = "main"
+	}
+	content, err := g.pdfHtml(pkg, pkgName)
+	if err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp("", "kcl-doc-pdf")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	input := filepath.Join(tmpDir, fmt.Sprintf("%s.html", pkgName))
+	if err := os.WriteFile(input, content, 0644); err != nil {
+		return err
+	}
+	output := filepath.Join(g.Target, fmt.Sprintf("%s.pdf", pkgName))
+	if out, err := exec.Command(converter, input, output).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to convert the doc to PDF with %s: %s\n%s", converter, err, out)
+	}
+	return nil
+}
+
+// pdfHtml renders the standalone HTML page of the package doc for the PDF conversion
+func (g *GenContext) pdfHtml(pkg *KclPackage, pkgName string) ([]byte, error) {
+	var mdBuf bytes.Buffer
+	err := g.Template.ExecuteTemplate(&mdBuf, "packageDoc", struct {
+		EscapeHtml bool
+		Data       *KclPackage
+	}{
+		EscapeHtml: g.EscapeHtml,
+		Data:       pkg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render package %s with template, err: %s", pkg.Name, err)
+	}
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.Table),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(goldmarkHtml.WithUnsafe()),
+	)
+	var body bytes.Buffer
+	if err := md.Convert(mdBuf.Bytes(), &body); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(pkgName), pdfStyle)
+	buf.WriteString("<div class=\"cover\">\n")
+	fmt.Fprintf(&buf, "<h1>%s</h1>\n", html.EscapeString(pkgName))
+	if pkg.Version != "" {
+		fmt.Fprintf(&buf, "<p>Version %s</p>\n", html.EscapeString(pkg.Version))
+	}
+	if summary := firstSentence(docText(pkg.Description)); summary != "" {
+		fmt.Fprintf(&buf, "<p>%s</p>\n", html.EscapeString(summary))
+	}
+	buf.WriteString("</div>\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,24 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// requiredQuickRef renders the required attributes of the schema with their types in one line, each linked to the
+// attribute row, such as "**Required:** [name](#server-name) `str`, [port](#server-port) `int`". The deprecated
+// attributes are omitted if IgnoreDeprecated is set, and the internal attributes are omitted if HideInternal is set
+func (g *GenContext) requiredQuickRef(schema *KclOpenAPIType) string {
+	var refs []string
+	for _, name := range getSortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		if !containsString(schema.Required, name) || (g.IgnoreDeprecated && prop.isDeprecated()) || (g.HideInternal && prop.isInternal(name)) {
+			continue
+		}
+		refs = append(refs, fmt.Sprintf("[%s](#%s) `%s`", name, attributeAnchorId(schema, name), prop.GetKclTypeName(false, false, false)))
+	}
+	if len(refs) == 0 {
+		return ""
+	}
+	return "**Required:** " + strings.Join(refs, ", ")
+}
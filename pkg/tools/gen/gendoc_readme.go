@@ -0,0 +1,112 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const packageReadmeFile = "README.md"
+
+// stabilityBadgeColors is the badge colors of the schema stabilities, other stabilities are in the default color
+var stabilityBadgeColors = map[string]string{
+	"stable":       "brightgreen",
+	"beta":         "yellow",
+	"experimental": "orange",
+	"deprecated":   "red",
+}
+
+// renderPackageReadmes writes a README.md for each package into the directory mirroring the package under the
+// target directory. The README contains the package docstring, a badge row of the schema count and stabilities, and
+// the schemas with their summaries linking to the package doc.
+func (g *GenContext) renderPackageReadmes(spec *SwaggerV2Spec) error {
+	if g.Format != Markdown && g.Format != Html {
+		return nil
+	}
+	pkg := spec.toKclPackage()
+	pkg.sortSchemasAndPkgs()
+	pkgName := pkg.Name
+	if pkg.Name == "" {
+		pkgName = "main"
+	}
+	docFileName := fmt.Sprintf("%s.%s", pkgName, g.Format)
+	if g.TechDocs {
+		docFileName = techDocsIndexFile
+	}
+	return g.renderPackageReadme(pkg, pkgName, ".", docFileName)
+}
+
+// renderPackageReadme writes the README.md of the package in the slash-separated directory relative to the target
+// directory, and the ones of the sub packages
+func (g *GenContext) renderPackageReadme(pkg *KclPackage, pkgName string, dir string, docFileName string) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", pkgName)
+	if dir == "." && pkg.Description != "" {
+		fmt.Fprintf(&buf, "%s\n\n", g.localizedDocText(pkg.Description))
+	}
+	buf.WriteString(packageBadges(pkg.SchemaList))
+	// the link from the package directory to the package doc in the target directory
+	docLink := docFileName
+	if dir != "." {
+		docLink = strings.Repeat("../", strings.Count(dir, "/")+1) + docFileName
+	}
+	if len(pkg.SchemaList) > 0 {
+		buf.WriteString("\n## Schemas\n\n| schema | stability | summary |\n| --- | --- | --- |\n")
+		for _, schema := range pkg.SchemaList {
+			name := schema.KclExtensions.XKclModelType.Type
+			summary := firstSentence(g.localizedDocText(schema.Description))
+			fmt.Fprintf(&buf, "|[%s](%s#%s)|%s|%s|\n", name, docLink, strings.ToLower(name), schema.stability(), strings.Replace(summary, "|", "\\|", -1))
+		}
+	}
+	if len(pkg.SubPackageList) > 0 {
+		buf.WriteString("\n## Packages\n\n")
+		for _, sub := range pkg.SubPackageList {
+			fmt.Fprintf(&buf, "- [%s](%s/%s)\n", sub.Name, sub.Name, packageReadmeFile)
+		}
+	}
+	buf.WriteString("\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n")
+
+	target := filepath.Join(g.Target, filepath.FromSlash(dir))
+	if err := g.writeOutput(target, packageReadmeFile, buf.Bytes()); err != nil {
+		return err
+	}
+	for _, sub := range pkg.SubPackageList {
+		if err := g.renderPackageReadme(sub, sub.Name, path.Join(dir, sub.Name), docFileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packageBadges renders the badge row of the schema count and the number of the schemas of each stability
+func packageBadges(schemas []*KclOpenAPIType) string {
+	stabilities := map[string]int{}
+	for _, schema := range schemas {
+		stabilities[schema.stability()]++
+	}
+	badges := []string{badge("schemas", len(schemas), "blue")}
+	names := make([]string, 0, len(stabilities))
+	for name := range stabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		color, ok := stabilityBadgeColors[name]
+		if !ok {
+			color = "lightgrey"
+		}
+		badges = append(badges, badge(name, stabilities[name], color))
+	}
+	return strings.Join(badges, " ") + "\n"
+}
+
+// badge renders the shields.io badge of the count
+func badge(label string, count int, color string) string {
+	// the dashes in the label are escaped by doubling them in the shields.io badge path
+	escaped := url.PathEscape(strings.ReplaceAll(label, "-", "--"))
+	return fmt.Sprintf("![%s: %d](https://img.shields.io/badge/%s-%d-%s)", label, count, escaped, count, color)
+}
@@ -0,0 +1,62 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// collectRelatedSchemas resolves the `@related <SchemaName>` tags of the attributes to the notes linking to the
+// related schemas. The target is the qualified schema name such as `models.Server`, or the schema name if it is
+// unique. The unknown targets are rendered as the text, reported as warnings and recorded in the UnresolvedLinks.
+func (g *GenContext) collectRelatedSchemas(spec *SwaggerV2Spec) {
+	g.relatedNotes = map[string]string{}
+	g.UnresolvedLinks = nil
+	byName := map[string][]string{}
+	for id := range spec.Definitions {
+		byName[schemaShortName(id)] = append(byName[schemaShortName(id)], id)
+	}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		for _, name := range getSortedKeys(schema.Properties) {
+			targets := annotationValues(schema.Properties[name].Description, "related")
+			if len(targets) == 0 {
+				continue
+			}
+			links := make([]string, len(targets))
+			for i, target := range targets {
+				resolved, ok := resolveRelatedSchema(spec, byName, target)
+				if !ok {
+					links[i] = target
+					g.UnresolvedLinks = append(g.UnresolvedLinks, fmt.Sprintf("%s.%s: %s", id, name, target))
+					g.warnf("unknown related schema %s of attribute %s of schema %s", target, name, id)
+					continue
+				}
+				links[i] = fmt.Sprintf("[%s](#%s)", target, strings.ToLower(schemaShortName(resolved)))
+			}
+			g.relatedNotes[id+"."+name] = "Related: " + strings.Join(links, ", ")
+		}
+	}
+}
+
+// resolveRelatedSchema returns the id of the related schema by the qualified name, or by the schema name if it is
+// unique in the package
+func resolveRelatedSchema(spec *SwaggerV2Spec, byName map[string][]string, target string) (string, bool) {
+	if _, ok := spec.Definitions[target]; ok {
+		return target, true
+	}
+	if ids := byName[target]; len(ids) == 1 {
+		return ids[0], true
+	}
+	return "", false
+}
+
+// verifyLinks fails the generation with the unresolved links if VerifyLinks is set
+func (g *GenContext) verifyLinks() error {
+	if !g.VerifyLinks || len(g.UnresolvedLinks) == 0 {
+		return nil
+	}
+	links := append([]string{}, g.UnresolvedLinks...)
+	sort.Strings(links)
+	return fmt.Errorf("found unresolved links:\n  %s", strings.Join(links, "\n  "))
+}
@@ -0,0 +1,89 @@
+package gen
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultSourceSectionTitle is the default title of the source files section of the schema
+const defaultSourceSectionTitle = "Source Files"
+
+// sourceSectionTitle returns the title of the source files section of the schema
+func (g *GenContext) sourceSectionTitle() string {
+	if g.SourceSectionTitle == "" {
+		return defaultSourceSectionTitle
+	}
+	return g.SourceSectionTitle
+}
+
+// schemaSourceFiles returns the source files of the schema relative to the package root. The file where the schema
+// is declared comes first, followed by the other files in the same package which declare the schema again
+func (g *GenContext) schemaSourceFiles(schema *KclOpenAPIType) []string {
+	declared := schema.KclExtensions.XKclModelType.Import.Alias
+	files := []string{sourceFilePath(schema.GetSchemaPkgDir(""), declared)}
+	names, readSource := g.packageSources(schema)
+	var others []string
+	for _, name := range names {
+		if name == declared || filepath.Ext(name) != ".k" {
+			continue
+		}
+		for _, line := range strings.Split(readSource(name), "\n") {
+			if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schema.KclExtensions.XKclModelType.Type {
+				others = append(others, sourceFilePath(schema.GetSchemaPkgDir(""), name))
+				break
+			}
+		}
+	}
+	sort.Strings(others)
+	files = append(files, others...)
+	for i, f := range files {
+		files[i] = g.resolveSourcePath(f)
+	}
+	return files
+}
+
+// packageSources returns the file names in the package directory of the schema and the reader of their sources,
+// which are the virtual sources when rendering from the sources in memory
+func (g *GenContext) packageSources(schema *KclOpenAPIType) ([]string, func(name string) string) {
+	if g.virtualSources != nil {
+		names := make([]string, 0, len(g.virtualSources))
+		for name := range g.virtualSources {
+			names = append(names, name)
+		}
+		return names, func(name string) string {
+			return g.virtualSources[name]
+		}
+	}
+	dir := schema.GetSchemaPkgDir(g.PackagePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, func(name string) string {
+		return readSchemaSource(filepath.Join(dir, name))
+	}
+}
+
+// resolveSourcePath rewrites the source file path with the SourcePathResolver, and the result is slash-separated as
+// well so the docs render the same paths on Unix and Windows
+func (g *GenContext) resolveSourcePath(p string) string {
+	if g.SourcePathResolver == nil {
+		return p
+	}
+	return strings.ReplaceAll(g.SourcePathResolver(p), "\\", "/")
+}
+
+// sourceFilePath joins the package directory and the file name to the slash-separated path, so the docs render
+// the same paths on Unix and Windows
+func sourceFilePath(pkgDir string, name string) string {
+	return path.Join(strings.ReplaceAll(pkgDir, "\\", "/"), strings.ReplaceAll(name, "\\", "/"))
+}
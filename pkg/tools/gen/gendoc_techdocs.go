@@ -0,0 +1,56 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	techDocsIndexFile  = "index.md"
+	techDocsConfigFile = "mkdocs.yml"
+)
+
+// techDocsFrontMatter returns the front matter of the package page in the Backstage TechDocs layout
+func techDocsFrontMatter(pkg *KclPackage, pkgName string) (string, error) {
+	frontMatter := yaml.MapSlice{{Key: "title", Value: pkgName}}
+	if summary := firstSentence(docText(pkg.Description)); summary != "" {
+		frontMatter = append(frontMatter, yaml.MapItem{Key: "description", Value: summary})
+	}
+	content, err := yaml.Marshal(frontMatter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("---\n%s---\n\n", content), nil
+}
+
+// renderTechDocsConfig writes the mkdocs.yml next to the docs directory, which is the layout Backstage TechDocs expects
+func (g *GenContext) renderTechDocsConfig(pkgName string) error {
+	config := yaml.MapSlice{
+		{Key: "site_name", Value: pkgName},
+		{Key: "docs_dir", Value: filepath.Base(g.Target)},
+		{Key: "nav", Value: []yaml.MapSlice{{{Key: pkgName, Value: techDocsIndexFile}}}},
+		{Key: "plugins", Value: []string{"techdocs-core"}},
+	}
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	rootDir := filepath.Dir(g.Target)
+	err = g.writeOutput(rootDir, techDocsConfigFile, content)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// firstSentence returns the first sentence of the text in one line
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
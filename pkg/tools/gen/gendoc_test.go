@@ -4,11 +4,17 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"github.com/goccy/go-yaml"
 	assert2 "github.com/stretchr/testify/assert"
+	"html"
+	"kcl-lang.io/kcl-go/scripts"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestIndexContent(t *testing.T) {
@@ -212,3 +218,1726 @@ func readLines(path string) ([]string, error) {
 	}
 	return lines, scanner.Err()
 }
+
+func TestDeprecatedEnumValues(t *testing.T) {
+	spec := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Job": {
+				Type: Object,
+				Properties: map[string]*KclOpenAPIType{
+					"policy": {
+						Type:        Object,
+						Description: "The restart policy.\n@deprecated \"OnFailure\": use \"Never\" instead",
+						KclExtensions: &KclExtensions{
+							XKclUnionTypes: []*KclOpenAPIType{
+								{Type: String, ReadOnly: true, Default: `"Always"`, Enum: []string{`"Always"`}},
+								{Type: String, ReadOnly: true, Default: `"OnFailure"`, Enum: []string{`"OnFailure"`}},
+								{Type: String, ReadOnly: true, Default: `"Never"`, Enum: []string{`"Never"`}},
+							},
+						},
+					},
+					// the deprecations of the attributes which are not the literal union values keep the type
+					"timeout": {
+						Type:        Integer,
+						Format:      Int64,
+						Description: "The timeout.\n@deprecated Use the deadline instead",
+					},
+					"mode": {
+						Type:        Object,
+						Description: "The mode.\n@deprecated \"Legacy\": removed",
+						KclExtensions: &KclExtensions{
+							XKclUnionTypes: []*KclOpenAPIType{
+								{Type: String, ReadOnly: true, Default: `"Fast"`, Enum: []string{`"Fast"`}},
+								{Type: String, ReadOnly: true, Default: `"Safe"`, Enum: []string{`"Safe"`}},
+							},
+						},
+					},
+				},
+				KclExtensions: &KclExtensions{
+					XKclModelType: &XKclModelType{
+						Type:   "Job",
+						Import: &KclModelImportInfo{},
+					},
+				},
+			},
+		},
+	}
+	tCases := []struct {
+		ignoreDeprecated bool
+		expect           string
+	}{
+		{
+			ignoreDeprecated: false,
+			expect:           `|**policy**<br />Optional (may be omitted)|"Always" \| ~~"OnFailure"~~ (deprecated: use "Never" instead) \| "Never"|The restart policy.||`,
+		},
+		{
+			ignoreDeprecated: true,
+			expect:           `|**policy**<br />Optional (may be omitted)|"Always" \| "Never"|The restart policy.||`,
+		},
+	}
+	for _, tCase := range tCases {
+		g := newTestGenContext(t, Markdown)
+		g.IgnoreDeprecated = tCase.ignoreDeprecated
+		got := renderTestDoc(t, g, spec)["main.md"]
+		assert2.Contains(t, got, tCase.expect)
+		assert2.Contains(t, got, `|**timeout**<br />Optional (may be omitted)|int|The timeout.||`)
+		assert2.Contains(t, got, `|**mode**<br />Optional (may be omitted)|"Fast" \| "Safe"|The mode.||`)
+		assert2.NotContains(t, got, "@deprecated")
+	}
+}
+
+// newTestGenContext creates a GenContext which outputs docs to a temporary directory
+func newTestGenContext(t *testing.T, format Format) *GenContext {
+	genOpts := GenOpts{
+		Path:       ".",
+		Format:     string(format),
+		Target:     t.TempDir(),
+		EscapeHtml: true,
+	}
+	g, err := genOpts.ValidateComplete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// renderTestDoc renders the spec and returns the generated files content by their relative paths
+func renderTestDoc(t *testing.T, g *GenContext, spec *SwaggerV2Spec) map[string]string {
+	if err := g.renderLocales(spec); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	err := filepath.Walk(g.Target, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(g.Target, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestAutolinkAttributes(t *testing.T) {
+	spec := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Scaler": {
+				Type:        Object,
+				Description: "Scales between `min_replicas` and `max_replicas`.",
+				Properties: map[string]*KclOpenAPIType{
+					"min_replicas": {
+						Type:        Integer,
+						Format:      Int64,
+						Description: "Must not exceed `max_replicas`, see `max` and `max_replicas_`.",
+					},
+					"max_replicas": {
+						Type:   Integer,
+						Format: Int64,
+					},
+				},
+				KclExtensions: &KclExtensions{
+					XKclModelType: &XKclModelType{
+						Type:   "Scaler",
+						Import: &KclModelImportInfo{},
+					},
+				},
+			},
+		},
+	}
+	g := newTestGenContext(t, Markdown)
+	g.AutolinkAttributes = true
+	got := renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "Scales between [`min_replicas`](#scaler-min_replicas) and [`max_replicas`](#scaler-max_replicas).")
+	assert2.Contains(t, got, `|<a id="scaler-min_replicas"></a>**min_replicas**<br />Optional (may be omitted)|int|Must not exceed [`+"`max_replicas`"+`](#scaler-max_replicas), see `+"`max` and `max_replicas_`"+`.||`)
+	assert2.Contains(t, got, `|<a id="scaler-max_replicas"></a>**max_replicas**<br />Optional (may be omitted)|`)
+
+	g = newTestGenContext(t, Markdown)
+	got = renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "|**min_replicas**<br />Optional (may be omitted)|int|Must not exceed `max_replicas`, see `max` and `max_replicas_`.||")
+
+	// the anchors are kept in the html docs, so the links resolve
+	g = newTestGenContext(t, Html)
+	g.AutolinkAttributes = true
+	got = renderTestDoc(t, g, spec)["main.html"]
+	assert2.Contains(t, got, `Scales between <a href="#scaler-min_replicas"><code>min_replicas</code></a>`)
+	assert2.Contains(t, got, `<a id="scaler-min_replicas"></a><strong>min_replicas</strong>`)
+	assert2.NotContains(t, got, "raw HTML omitted")
+
+	// the anchors of the schemas of the same name in different packages are qualified by the packages
+	scaler := newTestSchema("Scaler", "", nil, nil)
+	scaler.KclExtensions.XKclModelType.Import.Package = "autoscaling.v1"
+	assert2.Equal(t, "autoscaling-v1-scaler-min_replicas", attributeAnchorId(scaler, "min_replicas"))
+	assert2.Equal(t, "scaler-min_replicas", attributeAnchorId(spec.Definitions["Scaler"], "min_replicas"))
+}
+
+func TestSchemaExamples(t *testing.T) {
+	newSpec := func(example string) *SwaggerV2Spec {
+		return &SwaggerV2Spec{
+			Definitions: map[string]*KclOpenAPIType{
+				"Server": {
+					Type:        Object,
+					Description: "Server is a server.\n@example\n" + example,
+					Properties: map[string]*KclOpenAPIType{
+						"host": {Type: String},
+						"port": {Type: Integer, Format: Int64},
+						"tls":  {Type: Bool},
+						"backend": {
+							Ref: SchemaId2Ref("Backend"),
+						},
+					},
+					Required: []string{"host", "port"},
+					KclExtensions: &KclExtensions{
+						XKclModelType: &XKclModelType{Type: "Server", Import: &KclModelImportInfo{}},
+					},
+				},
+				"Backend": {
+					Type: Object,
+					Properties: map[string]*KclOpenAPIType{
+						"weights": {
+							Type:                 Object,
+							AdditionalProperties: &KclOpenAPIType{Type: Integer, Format: Int64},
+							KclExtensions:        &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}},
+						},
+					},
+					KclExtensions: &KclExtensions{
+						XKclModelType: &XKclModelType{Type: "Backend", Import: &KclModelImportInfo{}},
+					},
+				},
+			},
+		}
+	}
+	valid := "host: example.com\nport: 443\nbackend:\n  weights:\n    a: 1"
+	invalid := "host: example.com\nport: \"443\"\nbackend:\n  weights:\n    a: heavy\nname: web"
+
+	g := newTestGenContext(t, Markdown)
+	g.ValidateSchemaExamples = true
+	got := renderTestDoc(t, g, newSpec(valid))["main.md"]
+	assert2.Contains(t, got, "Server is a server.\n")
+	assert2.Contains(t, got, "#### Examples\n\nThe schema is in the root package, no import is needed.\n\n```\n"+valid+"\n```\n")
+	assert2.NotContains(t, got, "@example")
+	assert2.Empty(t, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	g.ValidateSchemaExamples = true
+	err := g.render(newSpec(invalid))
+	assert2.EqualError(t, err, "the example of schema Server does not match the schema: backend.weights.a: expect int, got heavy; the instance: attribute 'name' is not defined in the schema; port: expect int, got 443")
+
+	g = newTestGenContext(t, Markdown)
+	got = renderTestDoc(t, g, newSpec(invalid))["main.md"]
+	assert2.Contains(t, got, "#### Examples\n\nThe schema is in the root package, no import is needed.\n\n```\n"+invalid+"\n```\n")
+	assert2.Equal(t, []string{"the example of schema Server does not match the schema: backend.weights.a: expect int, got heavy; the instance: attribute 'name' is not defined in the schema; port: expect int, got 443"}, g.Warnings)
+
+	// the required attributes can not be None even with the default values, the optional attributes can
+	spec := newSpec("host: null\nport: ~\ntls: null")
+	spec.Definitions["Server"].Properties["port"].Default = "80"
+	g = newTestGenContext(t, Markdown)
+	g.ValidateSchemaExamples = true
+	err = g.render(spec)
+	assert2.EqualError(t, err, "the example of schema Server does not match the schema: the instance: attribute 'host' is required and can't be None; the instance: attribute 'port' is required and can't be None")
+	server := spec.Definitions["Server"]
+	assert2.Empty(t, validateInstance(spec, server, map[string]interface{}{"host": "example.com"}, ""))
+	// the string None is a valid string
+	assert2.Empty(t, validateInstance(spec, server, map[string]interface{}{"host": "None", "port": 80}, ""))
+
+	// the examples are validated against the enums and the constraints of the unconditional schema checks
+	server.Properties["protocol"] = &KclOpenAPIType{Type: String, Enum: []string{`"TCP"`, `"UDP"`}}
+	server.Properties["tags"] = &KclOpenAPIType{Type: Array, Items: &KclOpenAPIType{Type: String}}
+	server.KclExtensions.XKclChecks = []*XKclCheck{
+		{Expr: "1 <= port <= 65535"},
+		{Expr: `regex.match(host, r"^[a-z.]+$")`},
+		{Expr: "len(tags) <= 2"},
+		{Expr: "port > 1024", Condition: "tls"},
+	}
+	assert2.Empty(t, validateInstance(spec, server, map[string]interface{}{"host": "example.com", "port": 443, "protocol": "TCP", "tags": []interface{}{"a"}, "tls": true}, ""))
+	assert2.Equal(t, []string{
+		"host: \"Example.com\" does not match the pattern ^[a-z.]+$",
+		"port: 70000 exceeds maximum 65535",
+		"protocol: HTTP is not one of the allowed values \"TCP\", \"UDP\"",
+		"tags: length 3 is longer than maximum length 2",
+	}, validateInstance(spec, server, map[string]interface{}{"host": "Example.com", "port": 70000, "protocol": "HTTP", "tags": []interface{}{"a", "b", "c"}}, ""))
+}
+
+func TestTechDocs(t *testing.T) {
+	spec := &SwaggerV2Spec{
+		Info: SpecInfo{
+			Title:       "models",
+			Description: "Models of the app. It contains the workload schemas.",
+		},
+		Definitions: map[string]*KclOpenAPIType{
+			"Server": newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+				"backend": {Ref: SchemaId2Ref("Backend")},
+			}),
+			"Backend": newTestSchema("Backend", "", nil, nil),
+		},
+	}
+	g := newTestGenContext(t, Markdown)
+	g.TechDocs = true
+	files := renderTestDoc(t, g, spec)
+	assert2.Equal(t, []string{"index.md"}, getSortedKeys(files))
+	assert2.True(t, strings.HasPrefix(files["index.md"], "---\ntitle: models\ndescription: Models of the app.\n---\n\n# models\n"))
+	assert2.Contains(t, files["index.md"], "|**backend**<br />Optional (may be omitted)|[Backend](#backend)|")
+	config, err := os.ReadFile(filepath.Join(filepath.Dir(g.Target), "mkdocs.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, "site_name: models\ndocs_dir: docs\nnav:\n- models: index.md\nplugins:\n- techdocs-core\n", string(config))
+
+	g = newTestGenContext(t, Html)
+	g.TechDocs = true
+	assert2.Error(t, g.render(spec))
+}
+
+func TestAttributeCardinality(t *testing.T) {
+	one, ten := 1, 10
+	spec := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Server": newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+				"hosts":   {Type: Array, Items: &KclOpenAPIType{Type: String}, MinItems: &one},
+				"ports":   {Type: Array, Items: &KclOpenAPIType{Type: Integer, Format: Int64}, MinItems: &one, MaxItems: &ten},
+				"aliases": {Type: Array, Items: &KclOpenAPIType{Type: String}},
+				"labels": {
+					Type:                 Object,
+					AdditionalProperties: &KclOpenAPIType{Type: String},
+					MaxProperties:        &ten,
+					KclExtensions:        &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}},
+				},
+				"name": {Type: String},
+			}),
+		},
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "|**hosts**<br />Optional (may be omitted)|[str]<br />1..* items|")
+	assert2.Contains(t, got, "|**ports**<br />Optional (may be omitted)|[int]<br />1..10 items|")
+	assert2.Contains(t, got, "|**aliases**<br />Optional (may be omitted)|[str]<br />0..* items|")
+	assert2.Contains(t, got, "|**labels**<br />Optional (may be omitted)|{str:str}<br />0..10 entries|")
+	assert2.Contains(t, got, "|**name**<br />Optional (may be omitted)|str|")
+}
+
+func TestCollapseInherited(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		base := newTestSchema("Base", "", []string{"name"}, map[string]*KclOpenAPIType{
+			"name":   {Type: String},
+			"labels": {Type: String},
+		})
+		server := newTestSchema("Server", "", []string{"name"}, map[string]*KclOpenAPIType{
+			"name":   {Type: String},
+			"labels": {Type: String},
+			"port":   {Type: Integer, Format: Int64},
+		})
+		server.KclExtensions.XKclBaseSchema = SchemaId2Ref("Base")
+		return &SwaggerV2Spec{
+			Definitions: map[string]*KclOpenAPIType{"Base": base, "Server": server},
+		}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "|**labels**<br />Optional (may be omitted)|str|||\n|**name** `required`|str|||\n|**port**<br />Optional (may be omitted)|int|||\n")
+	assert2.NotContains(t, got, "Inherited")
+
+	g = newTestGenContext(t, Markdown)
+	g.CollapseInherited = true
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "### Server\n\n#### Attributes\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n|**port**<br />Optional (may be omitted)|int|||\n\n#### Inherited Attributes\n\nInherited from Base (2 attributes).\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n|**labels**<br />Optional (may be omitted)|str|||\n|**name** `required`|str|||\n")
+	assert2.Equal(t, 1, strings.Count(got, "Inherited from"))
+
+	g = newTestGenContext(t, Html)
+	g.CollapseInherited = true
+	got = renderTestDoc(t, g, newSpec())["main.html"]
+	assert2.Contains(t, got, "<details>\n<summary>Inherited from Base (2 attributes)</summary>\n")
+	assert2.Contains(t, got, "|<strong>labels</strong><br />Optional (may be omitted)|str|||\n|<strong>name</strong> <code>required</code>|str|||</p>\n</details>\n")
+	assert2.Less(t, strings.Index(got, "<strong>port</strong>"), strings.Index(got, "<details>"))
+}
+
+func TestAttributePresence(t *testing.T) {
+	source := `schema Config:
+    """Config is a config.
+
+    a: str
+    """
+    a?: str
+    b: str | None
+    c?: str | None = None
+    d: {str:str | None}
+    e: "x" | "y"
+
+    check:
+        b: None
+`
+	assert2.Equal(t, []string{"b", "c"}, parseNullableAttributes(source, "Config"))
+
+	spec := &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"Config": newTestSchema("Config", "", []string{"b", "d"}, map[string]*KclOpenAPIType{
+				"a": {Type: String},
+				"b": {Type: String, Nullable: true},
+				"c": {Type: String, Nullable: true},
+				"d": {Type: String},
+			}),
+		},
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "|**a**<br />Optional (may be omitted)|str|")
+	assert2.Contains(t, got, "|**b** `required`<br />Nullable (may be `None`)|str|")
+	assert2.Contains(t, got, "|**c**<br />Optional (may be omitted), nullable (may be `None`)|str|")
+	assert2.Contains(t, got, "|**d** `required`|str|")
+}
+
+func TestEvaluatedExample(t *testing.T) {
+	code, err := exampleInstanceCode("Server", "host: example.com\nports: [80, 443]\ntls: true\nbackend: null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, `__example__ = Server {"backend": None, "host": "example.com", "ports": [80, 443], "tls": True}`+"\n", code)
+
+	pkgPath := t.TempDir()
+	// the base schema in another file of the package is evaluated with the schema
+	sources := map[string]string{
+		"server.k": "schema Server(Base):\n    host: str\n    port: int = 80\n",
+		"base.k":   "schema Base:\n    protocol: str = \"http\"\n",
+	}
+	for name, source := range sources {
+		if err := os.WriteFile(filepath.Join(pkgPath, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := newTestSchema("Server", "@example\nhost: example.com", []string{"host", "port", "protocol"}, map[string]*KclOpenAPIType{
+		"host":     {Type: String},
+		"port":     {Type: Integer, Format: Int64, Default: "80"},
+		"protocol": {Type: String, Default: `"http"`},
+	})
+	server.KclExtensions.XKclModelType.Import.Alias = "server.k"
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	g := newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	g.ShowEvaluatedExample = true
+	got := renderTestDoc(t, g, spec)["main.md"]
+	if len(g.Warnings) > 0 {
+		t.Skipf("the KCL runtime is not available: %s", g.Warnings[0])
+	}
+	assert2.Contains(t, got, "```\nhost: example.com\n```\n\nEvaluated result:\n\n```yaml\nhost: example.com\nport: 80\nprotocol: http\n```\n")
+}
+
+func TestSourceFiles(t *testing.T) {
+	pkgPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(pkgPath, "models"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"server.k":     "schema Server:\n    host: str\n",
+		"server_ext.k": "schema Server:\n    port: int\n",
+		"other.k":      "schema ServerConfig:\n    name: str\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(pkgPath, "models", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+		server.KclExtensions.XKclModelType.Import = &KclModelImportInfo{Package: "models", Alias: "server.k"}
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"models.Server": server}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.NotContains(t, got, "Source Files")
+
+	g = newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	g.ShowSourceFiles = true
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "#### Source Files\n\n- models/server.k\n- models/server_ext.k\n\n")
+
+	g = newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	g.ShowSourceFiles = true
+	g.SourceSectionTitle = "Defined In"
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "#### Defined In\n\n- models/server.k\n")
+	assert2.NotContains(t, got, "Source Files")
+
+	g = newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	g.ShowSourceFiles = true
+	var originals []string
+	g.SourcePathResolver = func(original string) string {
+		originals = append(originals, original)
+		return `src\kcl\` + strings.ReplaceAll(original, "/", `\`)
+	}
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "#### Source Files\n\n- src/kcl/models/server.k\n- src/kcl/models/server_ext.k\n\n")
+	assert2.Equal(t, []string{"models/server.k", "models/server_ext.k"}, originals)
+
+	assert2.Equal(t, "models/v1/server.k", sourceFilePath("models/v1", "server.k"))
+	assert2.Equal(t, "models/v1/server.k", sourceFilePath(`models\v1`, "server.k"))
+	assert2.Equal(t, "server.k", sourceFilePath("", "server.k"))
+}
+
+func TestAttributeGroups(t *testing.T) {
+	server := newTestSchema("Server", "Server is a server.", nil, map[string]*KclOpenAPIType{
+		"image": {Type: String}, "build": {Type: String}, "cert": {Type: String}, "key": {Type: String},
+	})
+	server.KclExtensions.XKclAttributeGroups = []*XKclAttributeGroup{
+		{Kind: ExactlyOne, Attributes: []string{"image", "build"}},
+		{Kind: AtMostOne, Attributes: []string{"cert", "key"}},
+		{Kind: AtLeastOne, Attributes: []string{"image", "key"}},
+	}
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "Server is a server.\n\n> Exactly one of: `image`, `build`\n\n> At most one of: `cert`, `key`\n\n> At least one of: `image`, `key`\n\n#### Attributes\n")
+}
+
+func TestSchemaMetrics(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		node := newTestSchema("Node", "", nil, map[string]*KclOpenAPIType{
+			"children": {Type: Array, Items: &KclOpenAPIType{Ref: SchemaId2Ref("Node")}},
+			"leaf":     {Ref: SchemaId2Ref("Leaf")},
+		})
+		leaf := newTestSchema("Leaf", "", nil, map[string]*KclOpenAPIType{"value": {Type: String}})
+		leaf.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "value"}}
+		tree := newTestSchema("Tree", "", nil, map[string]*KclOpenAPIType{
+			"root":  {Ref: SchemaId2Ref("Node")},
+			"name":  {Type: String},
+			"leafs": {Type: Object, AdditionalProperties: &KclOpenAPIType{Ref: SchemaId2Ref("Leaf")}, KclExtensions: &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}}},
+		})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Node": node, "Leaf": leaf, "Tree": tree}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.NotContains(t, got, "Max depth")
+
+	g = newTestGenContext(t, Markdown)
+	g.IncludeMetrics = true
+	files := renderTestDoc(t, g, newSpec())
+	assert2.Contains(t, files["main.md"], "### Tree\n\n*Attributes: 3 · Max depth: 3 · Constraints: 0 · References: 2*\n\n#### Attributes\n")
+	assert2.Contains(t, files["main.md"], "### Leaf\n\n*Attributes: 1 · Max depth: 1 · Constraints: 1 · References: 0*\n\n#### Attributes\n")
+	assert2.JSONEq(t, `[
+		{"schema": "Tree", "attributes": 3, "depth": 3, "constraints": 0, "references": 2, "complexity": 8},
+		{"schema": "Node", "attributes": 2, "depth": 2, "constraints": 0, "references": 2, "complexity": 6},
+		{"schema": "Leaf", "attributes": 1, "depth": 1, "constraints": 1, "references": 0, "complexity": 3}
+	]`, files["metrics.json"])
+}
+
+func TestLocalizedDocs(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "Server is the common user interface for long-running services.\n\n@zh\nServer 是长期运行服务的通用接口。\n\n@ja\nServer は長時間実行されるサービスの共通インターフェースです。", nil, map[string]*KclOpenAPIType{
+			"name": {Type: String, Description: "The name of the server.\n@zh 服务的名称。"},
+		})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	}
+
+	// the short tags which are not the configured locales are not translations
+	tagged := newTestSchema("Server", "Server is the common interface.\n@id\nThe id is generated.", nil, map[string]*KclOpenAPIType{
+		"name": {Type: String, Description: "The name of the server.\n@to\nThe name is unique."},
+	})
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": tagged}})["main.md"]
+	assert2.Contains(t, got, "The id is generated.")
+	assert2.Contains(t, got, "The name is unique.")
+
+	g := newTestGenContext(t, Markdown)
+	g.Locale = "zh"
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "Server 是长期运行服务的通用接口。\n")
+	assert2.Contains(t, got, "服务的名称。")
+	assert2.NotContains(t, got, "long-running")
+
+	g = newTestGenContext(t, Markdown)
+	g.Locales = []string{"en", "zh", "ja"}
+	files := renderTestDoc(t, g, newSpec())
+	assert2.Contains(t, files["en/main.md"], "Server is the common user interface for long-running services.\n")
+	assert2.Contains(t, files["en/main.md"], "The name of the server.")
+	assert2.NotContains(t, files["en/main.md"], "服务")
+	assert2.Contains(t, files["zh/main.md"], "Server 是长期运行服务的通用接口。")
+	assert2.Contains(t, files["ja/main.md"], "Server は長時間実行されるサービスの共通インターフェースです。")
+	// the attribute without the japanese translation falls back to the untagged description
+	assert2.Contains(t, files["ja/main.md"], "The name of the server.")
+
+	g = newTestGenContext(t, Markdown)
+	g.Locales = []string{"../zh"}
+	assert2.Error(t, g.renderLocales(newSpec()))
+}
+
+func TestTableShowExample(t *testing.T) {
+	server := newTestSchema("Server", "", []string{"host", "port"}, map[string]*KclOpenAPIType{
+		"host":      {Type: String},
+		"port":      {Type: Integer, Format: Int64, Default: "80"},
+		"ratio":     {Type: Number, Format: Float},
+		"ports":     {Type: Array, Items: &KclOpenAPIType{Type: Integer, Format: Int64}},
+		"backend":   {Ref: SchemaId2Ref("Backend")},
+		"separator": {Type: String, Default: `"a|b"`},
+	})
+	backend := newTestSchema("Backend", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Backend": backend}}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.NotContains(t, got, "example |")
+
+	g := newTestGenContext(t, Markdown)
+	g.TableShowExample = true
+	got = renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "| name | type | description | default value | example |\n| --- | --- | --- | --- | --- |\n")
+	assert2.Contains(t, got, "|**port** `required`|int||80|80|\n")
+	assert2.Contains(t, got, "|**host** `required`|str|||\"string\"|\n")
+	assert2.Contains(t, got, "|1.0|\n")
+	assert2.Contains(t, got, "|[1]|\n")
+	assert2.Contains(t, got, "|Backend|\n")
+	assert2.Contains(t, got, "|\"a\\|b\"|\"a\\|b\"|\n")
+}
+
+func TestOrphanSchemas(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		app := newTestSchema("App", "", nil, map[string]*KclOpenAPIType{
+			"server":  {Ref: SchemaId2Ref("models.Server")},
+			"volumes": {Type: Array, Items: &KclOpenAPIType{Ref: SchemaId2Ref("models.Volume")}},
+		})
+		unused := newTestSchema("Unused", "", nil, map[string]*KclOpenAPIType{"next": {Ref: SchemaId2Ref("Unused")}})
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+		server.KclExtensions.XKclBaseSchema = SchemaId2Ref("models.Base")
+		server.KclExtensions.XKclMixins = []string{SchemaId2Ref("models.NameMixin")}
+		volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+		base := newTestSchema("Base", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		mixin := newTestSchema("NameMixin", "", nil, map[string]*KclOpenAPIType{"fullName": {Type: String}})
+		legacy := newTestSchema("Legacy", "", nil, map[string]*KclOpenAPIType{"owner": {Ref: SchemaId2Ref("models.Owner")}})
+		owner := newTestSchema("Owner", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		for _, s := range []*KclOpenAPIType{server, volume, base, mixin, legacy, owner} {
+			s.KclExtensions.XKclModelType.Import.Package = "models"
+		}
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{
+			"App": app, "Unused": unused, "models.Server": server, "models.Volume": volume, "models.Base": base,
+			"models.NameMixin": mixin, "models.Legacy": legacy, "models.Owner": owner,
+		}}
+	}
+
+	// the schemas of the root package are not exempt, and the references to the schema itself are not counted
+	orphans, err := orphanSchemas(newSpec(), nil)
+	assert2.NoError(t, err)
+	assert2.Equal(t, []string{"App", "Unused", "models.Legacy"}, orphans)
+	orphans, err = orphanSchemas(newSpec(), []string{"App", "models.Legacy"})
+	assert2.NoError(t, err)
+	assert2.Equal(t, []string{"Unused"}, orphans)
+	_, err = orphanSchemas(newSpec(), []string{"models.Unknown"})
+	assert2.Error(t, err)
+
+	g := newTestGenContext(t, Markdown)
+	renderTestDoc(t, g, newSpec())
+	assert2.Empty(t, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	g.Roots = []string{"App"}
+	renderTestDoc(t, g, newSpec())
+	assert2.Equal(t, []string{"schema Unused is not referenced by any schema or the roots", "schema models.Legacy is not referenced by any schema or the roots"}, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	g.Roots = []string{"App"}
+	g.ErrorOnOrphans = true
+	err = g.render(newSpec())
+	assert2.EqualError(t, err, "found schemas which are not referenced by any schema or the roots: Unused, models.Legacy")
+}
+
+func TestUnitDefaults(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		resources := newTestSchema("Resources", "", nil, map[string]*KclOpenAPIType{
+			"memory": {Type: Integer, Format: NumberMultiplier, Default: "1Gi"},
+			"cpu":    {Type: Integer, Format: NumberMultiplier, Default: "500m"},
+			"limit":  {Type: Integer, Format: NumberMultiplier},
+			"count":  {Type: Integer, Format: Int64, Default: "500"},
+		})
+		for _, prop := range resources.Properties {
+			prop.applyUnit()
+		}
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Resources": resources}}
+	}
+	spec := newSpec()
+	props := spec.Definitions["Resources"].Properties
+	assert2.Equal(t, "Gi", props["memory"].XKclUnit)
+	assert2.Equal(t, "m", props["cpu"].XKclUnit)
+	assert2.Nil(t, props["limit"].KclExtensions)
+	assert2.Nil(t, props["count"].KclExtensions)
+	assert2.Equal(t, "Gi", props["memory"].GetExtensionsMapping()[ExtensionKclUnit])
+
+	memory := exportJsonSchemaType(props["memory"], jsonSchemaFileName)
+	assert2.Equal(t, "1Gi", memory["default"])
+	assert2.Equal(t, "Gi", memory[ExtensionKclUnit])
+	cpu := exportJsonSchemaType(props["cpu"], jsonSchemaFileName)
+	assert2.Equal(t, "500m", cpu["default"])
+	assert2.Equal(t, "m", cpu[ExtensionKclUnit])
+
+	g := newTestGenContext(t, Markdown)
+	g.TableShowExample = true
+	got := renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "|**memory**<br />Optional (may be omitted)|units.NumberMultiplier||`1Gi`|1Gi|\n")
+	assert2.Contains(t, got, "|**cpu**<br />Optional (may be omitted)|units.NumberMultiplier||`500m`|500m|\n")
+	assert2.Contains(t, got, "|**limit**<br />Optional (may be omitted)|units.NumberMultiplier|||1Ki|\n")
+	assert2.Contains(t, got, "|**count**<br />Optional (may be omitted)|int||500|500|\n")
+}
+
+func TestEmitPDF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake converter is a shell script")
+	}
+	server := newTestSchema("Server", "Server is a server.", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+	volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+	spec := &SwaggerV2Spec{
+		Info:        SpecInfo{Title: "app", Version: "0.1.0", Description: "App is an application. It has servers."},
+		Definitions: map[string]*KclOpenAPIType{"Server": server, "Volume": volume},
+	}
+
+	// the fake converter copies the html input to the pdf output
+	converter := filepath.Join(t.TempDir(), "converter.sh")
+	if err := os.WriteFile(converter, []byte("#!/bin/sh\ncp \"$1\" \"$2\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	g := newTestGenContext(t, Html)
+	g.EmitPDF = true
+	g.PDFConverter = converter
+	files := renderTestDoc(t, g, spec)
+	assert2.Contains(t, files, "app.html")
+	pdf := files["app.pdf"]
+	assert2.Contains(t, pdf, "<div class=\"cover\">\n<h1>app</h1>\n<p>Version 0.1.0</p>\n<p>App is an application.</p>\n</div>\n")
+	assert2.Contains(t, pdf, "h3 { page-break-before: always; }")
+	assert2.Contains(t, pdf, `<a href="#server">Server</a>`)
+	assert2.Contains(t, pdf, `<h3 id="server">Server</h3>`)
+	assert2.Contains(t, pdf, `<h3 id="volume">Volume</h3>`)
+	assert2.Contains(t, pdf, "<table>")
+
+	g = newTestGenContext(t, Html)
+	g.EmitPDF = true
+	g.PDFConverter = filepath.Join(t.TempDir(), "missing")
+	err := g.render(spec)
+	assert2.ErrorContains(t, err, "the PDF converter")
+
+	g = newTestGenContext(t, Markdown)
+	g.EmitPDF = true
+	g.PDFConverter = converter
+	assert2.EqualError(t, g.render(spec), "the PDF output only supports the html format")
+}
+
+func TestAnyTypeAttribute(t *testing.T) {
+	server := newTestSchema("Server", "", []string{"metadata"}, map[string]*KclOpenAPIType{
+		"metadata": {Type: Object},
+		"labels":   {Type: Array, Items: &KclOpenAPIType{Type: Object}},
+		"extra":    {Type: Object, AdditionalProperties: &KclOpenAPIType{Type: Object}, KclExtensions: &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}}},
+		"names":    {Type: Array, Items: &KclOpenAPIType{Type: String}},
+	})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "|**metadata** `required`|`any` (unconstrained)<br />No type checking applies.|||\n")
+	// the untyped lists and dicts render the any elements
+	assert2.Contains(t, got, "|**labels**<br />Optional (may be omitted)|[any]<br />No type checking applies to the items.<br />0..* items|||\n")
+	assert2.Contains(t, got, "|**extra**<br />Optional (may be omitted)|{str:any}<br />No type checking applies to the values.<br />0..* entries|||\n")
+	assert2.Contains(t, got, "|**names**<br />Optional (may be omitted)|[str]<br />0..* items|||\n")
+
+	assert2.Equal(t, map[string]interface{}{}, exportJsonSchemaType(server.Properties["metadata"], jsonSchemaFileName))
+}
+
+func TestPackageReadme(t *testing.T) {
+	newSpec := func(description string) *SwaggerV2Spec {
+		server := newTestSchema("Server", "Server is a server. It serves.", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+		legacy := newTestSchema("Legacy", "Legacy is | old.", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+		legacy.KclExtensions.XKclDecorators = XKclDecorators{{Name: "deprecated"}}
+		volume := newTestSchema("Volume", "Volume is a volume.\n\n@stability experimental", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+		volume.KclExtensions.XKclModelType.Import.Package = "storage.v1"
+		return &SwaggerV2Spec{
+			Info:        SpecInfo{Title: "app", Description: description},
+			Definitions: map[string]*KclOpenAPIType{"Server": server, "Legacy": legacy, "storage.v1.Volume": volume},
+		}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	files := renderTestDoc(t, g, newSpec("App is an application."))
+	assert2.NotContains(t, files, "README.md")
+
+	g = newTestGenContext(t, Markdown)
+	g.EmitPackageReadme = true
+	files = renderTestDoc(t, g, newSpec("App is an application."))
+	assert2.Equal(t, `# app
+
+App is an application.
+
+![schemas: 2](https://img.shields.io/badge/schemas-2-blue) ![deprecated: 1](https://img.shields.io/badge/deprecated-1-red) ![stable: 1](https://img.shields.io/badge/stable-1-brightgreen)
+
+## Schemas
+
+| schema | stability | summary |
+| --- | --- | --- |
+|[Legacy](app.md#legacy)|deprecated|Legacy is \| old.|
+|[Server](app.md#server)|stable|Server is a server.|
+
+## Packages
+
+- [storage](storage/README.md)
+
+<!-- Auto generated by kcl-doc tool, please do not edit. -->
+`, files["README.md"])
+	assert2.Equal(t, `# storage
+
+![schemas: 0](https://img.shields.io/badge/schemas-0-blue)
+
+## Packages
+
+- [v1](v1/README.md)
+
+<!-- Auto generated by kcl-doc tool, please do not edit. -->
+`, files["storage/README.md"])
+	assert2.Contains(t, files["storage/v1/README.md"], "# v1\n\n![schemas: 1](https://img.shields.io/badge/schemas-1-blue) ![experimental: 1](https://img.shields.io/badge/experimental-1-orange)\n")
+	assert2.Contains(t, files["storage/v1/README.md"], "|[Volume](../../app.md#volume)|experimental|Volume is a volume.|\n")
+
+	// the package without the docstring
+	g = newTestGenContext(t, Markdown)
+	g.EmitPackageReadme = true
+	files = renderTestDoc(t, g, newSpec(""))
+	assert2.True(t, strings.HasPrefix(files["README.md"], "# app\n\n![schemas: 2]"))
+}
+
+func TestDefaultsShape(t *testing.T) {
+	pkgPath := t.TempDir()
+	source := `schema Backend:
+    name: str
+    weight: int = 1
+
+schema Server:
+    host: str
+    port: int = 80
+    tls: bool = False
+    protocols: [str] = ["http"]
+    backend: Backend
+`
+	if err := os.WriteFile(filepath.Join(pkgPath, "server.k"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "", []string{"host", "port", "tls", "protocols", "backend"}, map[string]*KclOpenAPIType{
+			"host":      {Type: String},
+			"port":      {Type: Integer, Format: Int64, Default: "80"},
+			"tls":       {Type: Bool, Default: "False"},
+			"protocols": {Type: Array, Items: &KclOpenAPIType{Type: String}, Default: `["http"]`},
+			"backend":   {Ref: SchemaId2Ref("Backend")},
+		})
+		server.KclExtensions.XKclModelType.Import.Alias = "server.k"
+		backend := newTestSchema("Backend", "", []string{"name", "weight"}, map[string]*KclOpenAPIType{
+			"name":   {Type: String},
+			"weight": {Type: Integer, Format: Int64, Default: "1"},
+		})
+		backend.KclExtensions.XKclModelType.Import.Alias = "server.k"
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Backend": backend}}
+	}
+
+	spec := newSpec()
+	approximated, err := approximateDefaultsShape(spec, spec.Definitions["Server"])
+	assert2.NoError(t, err)
+	assert2.Equal(t, `backend:
+  name: string
+  weight: 1
+host: string
+port: 80
+protocols:
+- http
+tls: false`, approximated)
+	assert2.Equal(t, unitPlaceholder("1Ki"), placeholderValue(spec, &KclOpenAPIType{Type: Integer, Format: NumberMultiplier}, map[string]bool{}))
+	// the quantity placeholder is the unit literal rather than the string in KCL
+	assert2.Equal(t, `{"memory": 1Ki}`, kclLiteral(map[string]interface{}{"memory": unitPlaceholder("1Ki")}))
+
+	g := newTestGenContext(t, Markdown)
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.NotContains(t, got, "#### Defaults")
+
+	g = newTestGenContext(t, Markdown)
+	g.PackagePath = pkgPath
+	g.ShowDefaultsShape = true
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "#### Defaults\n\n```yaml\n")
+
+	// the runtime result matches the approximation if the KCL runtime is available
+	evaluated, err := g.evaluateDefaultsShape(spec, spec.Definitions["Server"])
+	if err != nil {
+		// the fallback to the approximation is reported
+		assert2.Contains(t, g.Warnings, fmt.Sprintf("failed to evaluate the defaults shape of schema Server, the shape is approximated from the defaults: %s", err))
+		t.Skipf("the KCL runtime is not available: %s", err)
+	}
+	var want, result interface{}
+	assert2.NoError(t, yaml.Unmarshal([]byte(approximated), &want))
+	assert2.NoError(t, yaml.Unmarshal([]byte(evaluated), &result))
+	assert2.Equal(t, want, result)
+}
+
+func TestSchemaFilterPatterns(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"backend": {Ref: SchemaId2Ref("internal.Backend")},
+			"volume":  {Ref: SchemaId2Ref("models.Volume")},
+		})
+		server.KclExtensions.XKclModelType.Import.Package = "models"
+		volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+		volume.KclExtensions.XKclModelType.Import.Package = "models"
+		backend := newTestSchema("Backend", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		backend.KclExtensions.XKclModelType.Import.Package = "internal"
+		fixture := newTestSchema("ServerTest", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		fixture.KclExtensions.XKclModelType.Import.Package = "models"
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{
+			"models.Server": server, "models.Volume": volume, "internal.Backend": backend, "models.ServerTest": fixture,
+		}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	g.ExcludePattern = `^internal\.|Test$`
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "### Server\n")
+	assert2.Contains(t, got, "### Volume\n")
+	assert2.NotContains(t, got, "### Backend")
+	assert2.NotContains(t, got, "ServerTest")
+	assert2.NotContains(t, got, "- internal")
+	// the references to the excluded schemas are the plain text
+	assert2.Contains(t, got, "|**backend**<br />Optional (may be omitted)|Backend|||\n")
+	assert2.Contains(t, got, "|**volume**<br />Optional (may be omitted)|[Volume](#volume)|||\n")
+
+	g = newTestGenContext(t, Markdown)
+	g.IncludePattern = `^models\.`
+	g.ExcludePattern = `Test$`
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "- [Server](#server)\n")
+	assert2.Contains(t, got, "- [Volume](#volume)\n")
+	assert2.NotContains(t, got, "ServerTest")
+	assert2.NotContains(t, got, "### Backend")
+
+	g = newTestGenContext(t, Markdown)
+	g.IncludePattern = `^models\.(`
+	assert2.ErrorContains(t, g.render(newSpec()), "invalid include pattern")
+	g = newTestGenContext(t, Markdown)
+	g.ExcludePattern = `[`
+	assert2.ErrorContains(t, g.GenDoc(), "invalid exclude pattern \"[\"")
+}
+
+func TestRelatedSchemas(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"backend": {Type: String, Description: "The backend name.\n@related Backend\n@related models.Volume\n@related Unknown"},
+			"port":    {Type: Integer, Format: Int64, Description: "@related Backend"},
+		})
+		backend := newTestSchema("Backend", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+		volume.KclExtensions.XKclModelType.Import.Package = "models"
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Backend": backend, "models.Volume": volume}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	got := renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "|The backend name.<br />Related: [Backend](#backend), [models.Volume](#volume), Unknown||\n")
+	assert2.Contains(t, got, "|**port**<br />Optional (may be omitted)|int|Related: [Backend](#backend)||\n")
+	assert2.Equal(t, []string{"Server.backend: Unknown"}, g.UnresolvedLinks)
+	assert2.Equal(t, []string{"unknown related schema Unknown of attribute backend of schema Server"}, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	g.VerifyLinks = true
+	assert2.EqualError(t, g.render(newSpec()), "found unresolved links:\n  Server.backend: Unknown")
+}
+
+func TestIncrementalDoc(t *testing.T) {
+	root := t.TempDir()
+	target := t.TempDir()
+	writeFile := func(dir string, rel string, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(root, "kcl.mod", "[package]\nname = \"root\"\n")
+	writeFile(root, "main.k", "schema App:\n    name: str\n")
+	writeFile(root, "sub/sub.k", "schema Sub:\n    id: int\n")
+	writeFile(root, "libs/core/kcl.mod", "[package]\nname = \"core\"\n")
+	writeFile(root, "libs/core/core.k", "schema Core:\n    id: int\n")
+	writeFile(root, "README.md", "not a source")
+	modules := []workspaceModule{{Path: root, Rel: "."}, {Path: filepath.Join(root, "libs", "core"), Rel: "libs/core"}}
+
+	g := newTestGenContext(t, Markdown)
+	g.Target = target
+	// generate renders the modules which are not up to date and returns the rendered ones
+	generate := func() []string {
+		options, err := g.docCacheOptions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache := readDocCache(target, g.Format, options)
+		var rendered []string
+		for _, m := range modules {
+			ok, err := cache.refresh(g, target, m.Rel, m.Path, nestedModules(modules, m), func(p *docCachePackage) error {
+				writeFile(target, path.Join(m.Rel, "main.md"), p.Hash)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				rendered = append(rendered, m.Rel)
+			}
+		}
+		if err := cache.write(target); err != nil {
+			t.Fatal(err)
+		}
+		return rendered
+	}
+	assert2.Equal(t, []string{".", "libs/core"}, generate())
+	assert2.Nil(t, generate())
+
+	options, err := g.docCacheOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := readDocCache(target, Markdown, options)
+	assert2.Equal(t, []string{"main.md"}, cache.Packages["."].Outputs)
+	assert2.Equal(t, []string{"libs/core/main.md"}, cache.Packages["libs/core"].Outputs)
+	assert2.Empty(t, readDocCache(target, Html, options).Packages)
+
+	// the changes in the nested module and the non-source files do not affect the parent module
+	writeFile(root, "libs/core/core.k", "schema Core:\n    id: str\n")
+	writeFile(root, "README.md", "changed")
+	assert2.Equal(t, []string{"libs/core"}, generate())
+	writeFile(root, "sub/sub.k", "schema Sub:\n    id: str\n")
+	assert2.Equal(t, []string{"."}, generate())
+	writeFile(root, "sub/new.k", "")
+	assert2.Equal(t, []string{"."}, generate())
+
+	// the missing outputs are rendered again
+	if err := os.Remove(filepath.Join(target, "libs", "core", "main.md")); err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, []string{"libs/core"}, generate())
+
+	// the changes of the options and the templates render all the packages again
+	g.IncludeMetrics = true
+	assert2.Equal(t, []string{".", "libs/core"}, generate())
+	assert2.Nil(t, generate())
+	g.SchemaDocTmpl += "\n"
+	assert2.Equal(t, []string{".", "libs/core"}, generate())
+	// the paths and the incremental mode options are not part of the options
+	g.PackagePath = root
+	assert2.Nil(t, generate())
+
+	g.ForceFull = true
+	assert2.Equal(t, []string{".", "libs/core"}, generate())
+}
+
+func TestIncrementalGenDoc(t *testing.T) {
+	pkg := t.TempDir()
+	target := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pkg, "kcl.mod"), []byte("[package]\nname = \"app\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pkg, "main.k"), []byte("schema App:\n    name: str\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(target, "docs", "app.md")
+	// generate runs the generation through the public API as the command line does
+	generate := func(includeMetrics bool) {
+		genOpts := GenOpts{Path: pkg, Format: string(Markdown), Target: target, Incremental: true}
+		g, err := genOpts.ValidateComplete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.IncludeMetrics = includeMetrics
+		if err := g.GenDoc(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generate(false)
+	if _, err := os.Stat(output); err != nil {
+		t.Fatal(err)
+	}
+	// the unchanged package is not rendered again, so the marked output is kept
+	if err := os.WriteFile(output, []byte("marked"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generate(false)
+	assert2.Equal(t, "marked", readFileString(t, output))
+	// the changed options render the package again
+	generate(true)
+	assert2.NotEqual(t, "marked", readFileString(t, output))
+}
+
+func TestImportStatements(t *testing.T) {
+	example := map[string]KclExample{"Default": {Value: "x = 1"}}
+	server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+		"pod":    {Ref: "#/definitions/k8s.core.PodSpec", KclExtensions: &KclExtensions{XKclImportAlias: "c"}},
+		"person": {Ref: "#/definitions/models.Person"},
+	})
+	server.Examples = example
+	person := newTestSchema("Person", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+	person.KclExtensions.XKclModelType.Import.Package = "models"
+	person.Examples = example
+	pod := newTestSchema("PodSpec", "", nil, map[string]*KclOpenAPIType{"image": {Type: String}})
+	pod.KclExtensions.XKclModelType.Import.Package = "k8s.core"
+	pod.Examples = example
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "models.Person": person, "k8s.core.PodSpec": pod}}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "### Server\n")
+	assert2.Contains(t, got, "#### Examples\n\nThe schema is in the root package, no import is needed.\n\n```\nx = 1\n```\n")
+	assert2.Contains(t, got, "#### Examples\n\nImport the package of the schema:\n\n```\nimport models\n```\n\n```\nx = 1\n```\n")
+	assert2.Contains(t, got, "#### Examples\n\nImport the package of the schema:\n\n```\nimport k8s.core as c\n```\n\n```\nx = 1\n```\n")
+
+	assert2.Equal(t, "c", mostUsedAlias(map[string]int{"c": 2, "core": 2, "k": 1}))
+}
+
+// recordLogger records the log events as the level, message and fields
+type recordLogger struct {
+	events []string
+}
+
+func (l *recordLogger) record(level string, msg string, fields []interface{}) {
+	l.events = append(l.events, level+" "+logLine(msg, fields))
+}
+
+func (l *recordLogger) Debug(msg string, fields ...interface{}) { l.record("debug", msg, fields) }
+func (l *recordLogger) Info(msg string, fields ...interface{})  { l.record("info", msg, fields) }
+func (l *recordLogger) Warn(msg string, fields ...interface{})  { l.record("warn", msg, fields) }
+
+func TestLogger(t *testing.T) {
+	job := newTestSchema("Job", "", nil, map[string]*KclOpenAPIType{
+		"policy": {
+			Type:        Object,
+			Description: "The restart policy.\n@deprecated \"OnFailure\": use \"Never\" instead",
+			KclExtensions: &KclExtensions{XKclUnionTypes: []*KclOpenAPIType{
+				{Type: String, ReadOnly: true, Default: `"Always"`, Enum: []string{`"Always"`}},
+				{Type: String, ReadOnly: true, Default: `"OnFailure"`, Enum: []string{`"OnFailure"`}},
+			}},
+		},
+		"backend": {Type: String, Description: "@related Unknown"},
+	})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Job": job}}
+
+	logger := &recordLogger{}
+	g := newTestGenContext(t, Markdown)
+	g.Logger = logger
+	g.IgnoreDeprecated = true
+	renderTestDoc(t, g, spec)
+	assert2.Contains(t, logger.events, `debug skipping the deprecated enum value value="OnFailure"`)
+	assert2.Contains(t, logger.events, "warn unknown related schema Unknown of attribute backend of schema Job")
+	assert2.Contains(t, logger.events, "info generating doc for package package=main format=md")
+
+	assert2.Equal(t, "msg a=1 b", logLine("msg", []interface{}{"a", 1, "b"}))
+}
+
+func TestCopyButtons(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"host": {Type: String},
+			"port": {Type: Integer, Format: Int64, KclExtensions: &KclExtensions{XKclTypeExpr: "Port"}},
+		})
+		server.Examples = map[string]KclExample{"Default": {Value: "host: example.com"}}
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Html), newSpec())["main.html"]
+	assert2.NotContains(t, got, "kcl-copy")
+
+	g := newTestGenContext(t, Html)
+	g.EnableCopyButtons = true
+	got = renderTestDoc(t, g, newSpec())["main.html"]
+	assert2.Contains(t, got, `<div class="kcl-copy-block"><button type="button" class="kcl-copy-button" aria-label="Copy to clipboard" title="Copy to clipboard">Copy</button><span class="kcl-copy-status" role="status" aria-live="polite"></span><pre><code>host: example.com
+</code></pre></div>`)
+	// the attribute types copy their type expressions
+	assert2.Contains(t, got, `<span class="kcl-copy-block kcl-copy-inline" data-copy="Port"><button type="button" class="kcl-copy-button" aria-label="Copy the type to clipboard" title="Copy the type to clipboard">Copy</button><span class="kcl-copy-status" role="status" aria-live="polite"></span></span><span class="kcl-type" title="Port">int</span>`)
+	assert2.Equal(t, 1, strings.Count(got, "<script>"))
+	assert2.NotContains(t, got, "src=")
+	assert2.NotContains(t, got, "http")
+
+	// the markdown docs are not changed
+	g = newTestGenContext(t, Markdown)
+	g.EnableCopyButtons = true
+	assert2.NotContains(t, renderTestDoc(t, g, newSpec())["main.md"], "kcl-copy")
+}
+
+func TestFacets(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		server := newTestSchema("Server", "Server is a server.\n\n@tag network, Load Balancing", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+		volume := newTestSchema("Volume", "Volume is a volume.\n\n@stability experimental\n@tag storage", nil, map[string]*KclOpenAPIType{"path": {Type: String}})
+		volume.KclExtensions.XKclModelType.Import.Package = "storage"
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "storage.Volume": volume}}
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Html), newSpec())["main.html"]
+	assert2.NotContains(t, got, "kcl-facets")
+	assert2.NotContains(t, got, "data-stability")
+
+	g := newTestGenContext(t, Html)
+	g.EnableFacets = true
+	got = renderTestDoc(t, g, newSpec())["main.html"]
+	assert2.Contains(t, got, `<h2>Index</h2>
+<div class="kcl-facets" role="group" aria-label="Filter the schemas">
+<label>Stability <select data-facet="stability"><option value="">All</option><option value="experimental">experimental</option><option value="stable">stable</option></select></label>
+<label>Tag <select data-facet="tags"><option value="">All</option><option value="load-balancing">load-balancing</option><option value="network">network</option><option value="storage">storage</option></select></label>
+</div>
+<ul>
+`)
+	assert2.Contains(t, got, `<li data-stability="stable" data-tags="network load-balancing"><a href="#server">Server</a></li>`)
+	assert2.Contains(t, got, `<li data-stability="experimental" data-tags="storage"><a href="#volume">Volume</a></li>`)
+	assert2.Equal(t, 1, strings.Count(got, "<script>"))
+	assert2.NotContains(t, got, "@tag")
+}
+
+func TestDefaultTypeMismatch(t *testing.T) {
+	newSpec := func(portDefault string) *SwaggerV2Spec {
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"host":     {Type: String, Default: `"example.com"`},
+			"port":     {Type: Integer, Format: Int64, Default: portDefault},
+			"ratio":    {Type: Number, Format: Float, Default: "1"},
+			"tls":      {Type: Bool, Default: "False"},
+			"memory":   {Type: Integer, Format: NumberMultiplier, Default: "1Gi"},
+			"tags":     {Type: Array, Items: &KclOpenAPIType{Type: String}, Default: `["a", 1]`},
+			"name":     {Type: String, Default: `"web" + "-" + "server"`},
+			"optional": {Type: String, Default: "None"},
+		})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	renderTestDoc(t, g, newSpec("80"))
+	assert2.Equal(t, []string{`the default value ["a", 1] of Server.tags[1]: expect str, got 1`}, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	renderTestDoc(t, g, newSpec(`"80"`))
+	assert2.Contains(t, g.Warnings, `the default value "80" of Server.port: expect int, got 80`)
+
+	g = newTestGenContext(t, Markdown)
+	g.ErrorOnTypeMismatch = true
+	err := g.renderLocales(newSpec(`"80"`))
+	assert2.EqualError(t, err, "found defaults which do not match the attribute types:\n"+
+		`  the default value "80" of Server.port: expect int, got 80`+"\n"+
+		`  the default value ["a", 1] of Server.tags[1]: expect str, got 1`)
+}
+
+func TestElementSummary(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		person := newTestSchema("Person", "Person is a person record. It has a name.", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		team := newTestSchema("Team", "", nil, map[string]*KclOpenAPIType{
+			"members": {Type: Array, Items: &KclOpenAPIType{Ref: "#/definitions/Person"}},
+			"leads":   {Type: Object, AdditionalProperties: &KclOpenAPIType{Ref: "#/definitions/Person"}, KclExtensions: &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}}},
+			"owner":   {Ref: "#/definitions/Person"},
+			"tags":    {Type: Array, Items: &KclOpenAPIType{Type: String}},
+		})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Person": person, "Team": team}}
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), newSpec())["main.md"]
+	assert2.NotContains(t, got, "person record.<br />")
+	assert2.NotContains(t, got, " — ")
+
+	g := newTestGenContext(t, Markdown)
+	g.ShowElementSummary = true
+	got = renderTestDoc(t, g, newSpec())["main.md"]
+	assert2.Contains(t, got, "|**members**<br />Optional (may be omitted)|[[Person](#person)] — Person is a person record.<br />0..* items|||\n")
+	assert2.Contains(t, got, "|**leads**<br />Optional (may be omitted)|{str:[Person](#person)} — Person is a person record.<br />0..* entries|||\n")
+	assert2.Contains(t, got, "|**owner**<br />Optional (may be omitted)|[Person](#person)|||\n")
+	assert2.Contains(t, got, "|**tags**<br />Optional (may be omitted)|[str]<br />0..* items|||\n")
+}
+
+func TestSchemaKinds(t *testing.T) {
+	assert2.Equal(t, MixinKind, parseSchemaKind("mixin NameMixin for NameProtocol:\n    name: str\n", "NameMixin"))
+	assert2.Equal(t, ProtocolKind, parseSchemaKind("protocol NameProtocol:\n    name: str\n", "NameProtocol"))
+	assert2.Equal(t, "", parseSchemaKind("schema Person:\n    name: str\n", "Person"))
+
+	person := newTestSchema("Person", "", []string{"name"}, map[string]*KclOpenAPIType{"name": {Type: String}})
+	mixin := newTestSchema("NameMixin", "", nil, map[string]*KclOpenAPIType{"fullName": {Type: String}})
+	mixin.KclExtensions.XKclKind = MixinKind
+	protocol := newTestSchema("NameProtocol", "", []string{"name"}, map[string]*KclOpenAPIType{
+		"name": {Type: String},
+		"age":  {Type: Integer, Format: Int64},
+	})
+	protocol.KclExtensions.XKclKind = ProtocolKind
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Person": person, "NameMixin": mixin, "NameProtocol": protocol}}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "### NameMixin\n\nKind: mixin\n")
+	assert2.Contains(t, got, "### NameProtocol\n\nKind: protocol\n\n```kcl\nprotocol NameProtocol:\n    age?: int\n    name: str\n```\n")
+	assert2.NotContains(t, got, "Kind: schema")
+	assert2.Contains(t, got, "### Schemas\n\n- [Person](#person)\n")
+	assert2.Contains(t, got, "### Mixins\n\n- [NameMixin](#namemixin)\n")
+	assert2.Contains(t, got, "### Protocols\n\n- [NameProtocol](#nameprotocol)\n")
+
+	got = renderTestDoc(t, newTestGenContext(t, Markdown), &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Person": person}})["main.md"]
+	assert2.NotContains(t, got, "### Schemas")
+}
+
+func TestPackageDeprecation(t *testing.T) {
+	assert2.Nil(t, parsePackageDeprecation("The legacy models."))
+	assert2.Equal(t, &packageDeprecation{Successor: "models"}, parsePackageDeprecation("The legacy models.\n\n@deprecated-use models"))
+
+	newSpec := func() *SwaggerV2Spec {
+		return &SwaggerV2Spec{
+			Info: SpecInfo{Title: "legacy", Description: "The legacy models.\n\n@deprecated The schemas are moved.\n@deprecated-use models"},
+			Definitions: map[string]*KclOpenAPIType{
+				"Person": newTestSchema("Person", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}}),
+			},
+		}
+	}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), newSpec())["legacy.md"]
+	assert2.Contains(t, got, "# legacy\n\n> **Deprecated**: The schemas are moved. Use `models` instead.\n\n## Overview\n\nThe legacy models.\n")
+
+	// the successor in the workspace links to its doc
+	g := newTestGenContext(t, Markdown)
+	g.packageDocLinks = map[string]string{"models": "libs/models/models.md"}
+	g.packageDocDir = "libs/legacy"
+	got = renderTestDoc(t, g, newSpec())["legacy.md"]
+	assert2.Contains(t, got, "> **Deprecated**: The schemas are moved. Use [models](../../libs/models/models.md) instead.\n")
+
+	g = newTestGenContext(t, Markdown)
+	g.IgnoreDeprecated = true
+	if err := g.renderLocales(newSpec()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := os.Stat(g.Target)
+	assert2.True(t, os.IsNotExist(err))
+}
+
+func TestEmbedToolVersion(t *testing.T) {
+	now := timeNow
+	defer func() { timeNow = now }()
+	timeNow = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	newSpec := func() *SwaggerV2Spec {
+		return &SwaggerV2Spec{
+			Info: SpecInfo{Title: "app"},
+			Definitions: map[string]*KclOpenAPIType{
+				"Person": newTestSchema("Person", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}}),
+			},
+		}
+	}
+	header := fmt.Sprintf("Generated by kcl-go %s with KCL %s", toolVersion(), scripts.KclvmAbiVersion)
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), newSpec())["app.md"]
+	assert2.NotContains(t, got, "Generated by kcl-go")
+
+	g := newTestGenContext(t, Markdown)
+	g.EmbedToolVersion = true
+	got = renderTestDoc(t, g, newSpec())["app.md"]
+	assert2.True(t, strings.HasPrefix(got, "<!-- "+header+" at 2024-01-02T03:04:05Z -->\n# app\n"), got)
+
+	g = newTestGenContext(t, Html)
+	g.EmbedToolVersion = true
+	g.Deterministic = true
+	got = renderTestDoc(t, g, newSpec())["app.html"]
+	assert2.True(t, strings.HasPrefix(got, `<meta name="generator" content="`+html.EscapeString(header)+`">`+"\n<h1>app</h1>"), got)
+
+	g = newTestGenContext(t, Jsonnet)
+	g.EmbedToolVersion = true
+	got = renderTestDoc(t, g, newSpec())["app.libsonnet"]
+	assert2.True(t, strings.HasPrefix(got, "// "+header+" at 2024-01-02T03:04:05Z\n"), got)
+}
+
+func TestFormatHints(t *testing.T) {
+	event := newTestSchema("Event", "", []string{"id", "at", "owner"}, map[string]*KclOpenAPIType{
+		"id":      {Type: String, Description: "The event id.\n@format uuid"},
+		"at":      {Type: String, Description: "@format date-time"},
+		"on":      {Type: String, Description: "@format Date"},
+		"timeout": {Type: String, Description: "@format duration"},
+		"owner":   {Type: String, Description: "@format email"},
+		"link":    {Type: String, Description: "@format uri"},
+		"color":   {Type: String, Description: "@format hex-color"},
+		"name":    {Type: String},
+	})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Event": event}}
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.Contains(t, got, "|**id** `required`|str<br />format: uuid, e.g. `123e4567-e89b-12d3-a456-426614174000`|The event id.||\n")
+	assert2.Contains(t, got, "|str<br />format: date-time, e.g. `2024-01-02T15:04:05Z`|")
+	assert2.Contains(t, got, "|str<br />format: date, e.g. `2024-01-02`|")
+	assert2.Contains(t, got, "|str<br />format: duration, e.g. `PT1H30M`|")
+	assert2.Contains(t, got, "|str<br />format: email, e.g. `user@example.com`|")
+	assert2.Contains(t, got, "|str<br />format: uri, e.g. `https://example.com/path`|")
+	// the unknown format is rendered without an example
+	assert2.Contains(t, got, "|str<br />format: hex-color|")
+	assert2.Contains(t, got, "|**name**<br />Optional (may be omitted)|str||")
+
+	// the placeholders of the required attributes are formatted
+	shape, err := approximateDefaultsShape(spec, event)
+	assert2.NoError(t, err)
+	assert2.Equal(t, `at: 2024-01-02T15:04:05Z
+id: 123e4567-e89b-12d3-a456-426614174000
+owner: user@example.com`, shape)
+}
+
+func TestRenderInMemory(t *testing.T) {
+	files := map[string]string{
+		"play/person.k":     "schema Person:\n    name: str\n",
+		"play/person_ext.k": "schema Person:\n    age: int\n",
+	}
+	dir, err := virtualPackageDir(files)
+	assert2.NoError(t, err)
+	assert2.Equal(t, "play", dir)
+	_, err = virtualPackageDir(map[string]string{"a/x.k": "", "b/y.k": ""})
+	assert2.Error(t, err)
+	_, err = virtualPackageDir(map[string]string{"x.json": ""})
+	assert2.Error(t, err)
+
+	person := newTestSchema("Person", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+	person.KclExtensions.XKclModelType.Import.Alias = "person.k"
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Person": person}}
+
+	g := newTestGenContext(t, Markdown)
+	g.ShowSourceFiles = true
+	g.IncludeMetrics = true
+	outputs, err := g.renderInMemory(spec, dir, files)
+	assert2.NoError(t, err)
+	assert2.Contains(t, outputs, "main.md")
+	assert2.Contains(t, outputs, metricsFile)
+	// the source files reference the virtual paths
+	assert2.Contains(t, string(outputs["main.md"]), "#### Source Files\n\n- play/person.k\n- play/person_ext.k\n\n")
+	// nothing is written to the disk
+	_, err = os.Stat(g.Target)
+	assert2.True(t, os.IsNotExist(err))
+}
+
+func TestRenderFromSource(t *testing.T) {
+	g := newTestGenContext(t, Markdown)
+	g.ShowSourceFiles = true
+	outputs, err := g.RenderFromSource(map[string]string{
+		"person.k":  "schema Person:\n    \"\"\"Person is a person.\"\"\"\n    name: str\n    address?: Address\n",
+		"address.k": "schema Address:\n    city: str\n\n    check:\n        len(city) >= 1\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(outputs["main.md"])
+	assert2.Contains(t, got, "### Person\n\nPerson is a person.\n")
+	assert2.Contains(t, got, "### Address\n")
+	assert2.Contains(t, got, "#### Source Files\n\n- person.k\n")
+	assert2.Contains(t, got, "#### Source Files\n\n- address.k\n")
+}
+
+func TestTypeExpressions(t *testing.T) {
+	source := `schema Config:
+    """Config is a config."""
+    name?: str | None
+    ports: {str: [int | str]} = {}
+    sizes: [int | None]
+`
+	assert2.Equal(t, []attributeTypeExpr{
+		{Name: "name", Expr: "str | None"},
+		{Name: "ports", Expr: "{str: [int | str]}"},
+		{Name: "sizes", Expr: "[int | None]"},
+	}, parseAttributeTypes(source, "Config"))
+
+	withExpr := func(tpe *KclOpenAPIType, expr string) *KclOpenAPIType {
+		if tpe.KclExtensions == nil {
+			tpe.KclExtensions = &KclExtensions{}
+		}
+		tpe.KclExtensions.XKclTypeExpr = expr
+		return tpe
+	}
+	newSpec := func() *SwaggerV2Spec {
+		config := newTestSchema("Config", "", nil, map[string]*KclOpenAPIType{
+			"name": withExpr(&KclOpenAPIType{Type: String, Nullable: true}, "str | None"),
+			"ports": withExpr(&KclOpenAPIType{Type: Object, AdditionalProperties: &KclOpenAPIType{Type: Array, Items: &KclOpenAPIType{Type: Object, KclExtensions: &KclExtensions{XKclUnionTypes: []*KclOpenAPIType{{Type: Integer, Format: Int64}, {Type: String}}}}},
+				KclExtensions: &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}}}, "{str: [int | str]}"),
+			"sizes": withExpr(&KclOpenAPIType{Type: Array, Items: &KclOpenAPIType{Type: Integer, Format: Int64, Nullable: true}}, "[int | None]"),
+			"port":  withExpr(&KclOpenAPIType{Type: Integer, Format: Int64}, "Port"),
+			"spec":  withExpr(&KclOpenAPIType{Ref: "#/definitions/Spec"}, "core.Spec"),
+		})
+		spec := newTestSchema("Spec", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Config": config, "Spec": spec}}
+	}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), newSpec())["main.md"]
+	assert2.Contains(t, got, "|str (`str \\| None`)|")
+	assert2.Contains(t, got, "|[int] (`[int \\| None]`)<br />0..* items|")
+	assert2.Contains(t, got, "|int (`Port`)|")
+	// the expressions rendering the same types as the labels are not repeated
+	assert2.Contains(t, got, "|{str:[int \\| str]}<br />0..* entries|")
+	assert2.Contains(t, got, "|[Spec](#spec)|")
+
+	got = renderTestDoc(t, newTestGenContext(t, Html), newSpec())["main.html"]
+	assert2.Contains(t, got, `<span class="kcl-type" title="[int | None]">[int]</span>`)
+	assert2.Contains(t, got, `<span class="kcl-type" title="Port">int</span>`)
+	assert2.NotContains(t, got, "⟦")
+}
+
+func TestChangelogSince(t *testing.T) {
+	newSpec := func() *SwaggerV2Spec {
+		person := newTestSchema("Person", "Person is a person. It has a name.\n@since 1.2.0", nil, map[string]*KclOpenAPIType{
+			"name": {Type: String, Description: "@since 1.2.0"},
+		})
+		server := newTestSchema("Server", "@since 1.0.0", nil, map[string]*KclOpenAPIType{
+			"host": {Type: String, Description: "The host.\n@since 1.0.0"},
+			"port": {Type: Integer, Format: Int64, Description: "The port.\n@since v1.2.0"},
+			"tls":  {Type: Bool, Description: "@since 1.3.0"},
+		})
+		server.KclExtensions.XKclModelType.Import.Package = "net"
+		volume := newTestSchema("Volume", "", nil, map[string]*KclOpenAPIType{"size": {Type: String}})
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Person": person, "net.Server": server, "Volume": volume}}
+	}
+	assert2.Equal(t, "# Changes in 1.2.0\n\n## main\n\n- Added the schema `Person`. Person is a person.\n\n## net\n\n- Added the attribute `Server.port`.\n\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n", MarkdownChangelogSince(newSpec(), "1.2.0"))
+	assert2.Equal(t, "# Changes in 2.0.0\n\nNo schemas or attributes are introduced in 2.0.0.\n\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n", MarkdownChangelogSince(newSpec(), "2.0.0"))
+
+	files := renderTestDoc(t, newTestGenContext(t, Markdown), newSpec())
+	assert2.NotContains(t, files, changelogDocFile)
+
+	g := newTestGenContext(t, Markdown)
+	g.ChangelogSince = "1.3.0"
+	files = renderTestDoc(t, g, newSpec())
+	assert2.Contains(t, files[changelogDocFile], "## net\n\n- Added the attribute `Server.tls`.\n")
+}
+
+func TestConstraintExamples(t *testing.T) {
+	server := newTestSchema("Server", "", []string{"port"}, map[string]*KclOpenAPIType{
+		"port":     {Type: Integer, Format: Int64, Description: "The listening port."},
+		"name":     {Type: String},
+		"hostname": {Type: String},
+		"protocol": {Type: String, Enum: []string{`"TCP"`, `"UDP"`}},
+		"weight":   {Type: Integer, Format: Int64},
+		"plain":    {Type: String},
+	})
+	server.KclExtensions.XKclChecks = []*XKclCheck{
+		{Expr: "1 <= port <= 65535"},
+		{Expr: "len(name) >= 1"},
+		{Expr: `regex.match(hostname, r"^[a-z][a-z0-9-]*$")`},
+		// the conditional checks are not applied
+		{Expr: "weight < 10", Condition: "plain"},
+	}
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.NotContains(t, got, "Valid:")
+
+	g := newTestGenContext(t, Markdown)
+	g.ShowConstraintExamples = true
+	got = renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "|The listening port.<br />Valid: `65535`<br />Invalid: `65536` (exceeds maximum 65535)|")
+	assert2.Contains(t, got, "|Valid: `\"a\"`<br />Invalid: `\"\"` (shorter than minimum length 1)|")
+	assert2.Contains(t, got, "|Valid: `\"a\"`<br />Invalid: `\"\"` (does not match the pattern ^[a-z][a-z0-9-]*$)|")
+	assert2.Contains(t, got, "|Valid: `\"TCP\"`<br />Invalid: `\"invalid\"` (not one of the allowed values)|")
+	assert2.Contains(t, got, "|**weight**<br />Optional (may be omitted)|int|||")
+
+	// the lower bound is violated when there is no upper bound
+	server.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "weight >= 0"}}
+	assert2.Equal(t, "Valid: `0`<br />Invalid: `-1` (below minimum 0)", constraintExamples(server, "weight"))
+	// the numeric enums are violated by a larger number
+	server.Properties["weight"].Enum = []string{"1", "5"}
+	assert2.Equal(t, "Valid: `1`<br />Invalid: `6` (not one of the allowed values)", constraintExamples(server, "weight"))
+
+	// the valid value of the pattern is fitted to the length bounds, and the invalid value violates the length bounds
+	server.Properties["code"] = &KclOpenAPIType{Type: String}
+	server.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "len(code) >= 1"}, {Expr: `regex.match(code, r"^[0-9]+$")`}}
+	assert2.Equal(t, "Valid: `\"0\"`<br />Invalid: `\"\"` (shorter than minimum length 1)", constraintExamples(server, "code"))
+	server.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "len(code) >= 3"}, {Expr: `regex.match(code, r"^[A-Z]+$")`}}
+	assert2.Equal(t, "Valid: `\"AAA\"`<br />Invalid: `\"AA\"` (shorter than minimum length 3)", constraintExamples(server, "code"))
+	server.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "len(code) <= 2"}, {Expr: `regex.match(code, r"^[0-9]+$")`}}
+	assert2.Equal(t, "Valid: `\"0\"`<br />Invalid: `\"000\"` (longer than maximum length 2)", constraintExamples(server, "code"))
+}
+
+func TestInheritanceConflicts(t *testing.T) {
+	literal := func(value string) *KclOpenAPIType {
+		return &KclOpenAPIType{Type: String, ReadOnly: true, Enum: []string{value}, Default: value}
+	}
+	newSpec := func(portType *KclOpenAPIType) *SwaggerV2Spec {
+		base := newTestSchema("Base", "", nil, map[string]*KclOpenAPIType{
+			"port":     {Type: Integer, Format: Int64},
+			"protocol": {Type: String},
+			"owner":    {Ref: SchemaId2Ref("Person")},
+			"ratio":    {Type: Number, Format: Float},
+		})
+		server := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"port": portType,
+			// the narrowed types are compatible
+			"protocol": {Type: Object, KclExtensions: &KclExtensions{XKclUnionTypes: []*KclOpenAPIType{literal(`"TCP"`), literal(`"UDP"`)}}},
+			"owner":    {Ref: SchemaId2Ref("Admin")},
+			"ratio":    {Type: Integer, Format: Int64},
+		})
+		server.KclExtensions.XKclBaseSchema = SchemaId2Ref("Base")
+		admin := newTestSchema("Admin", "", nil, nil)
+		admin.KclExtensions.XKclBaseSchema = SchemaId2Ref("Person")
+		return &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{
+			"Base": base, "Server": server, "Admin": admin, "Person": newTestSchema("Person", "", nil, nil),
+		}}
+	}
+
+	g := newTestGenContext(t, Markdown)
+	renderTestDoc(t, g, newSpec(&KclOpenAPIType{Type: Integer, Format: Int64}))
+	assert2.Empty(t, g.Warnings)
+
+	port := &KclOpenAPIType{Type: String, KclExtensions: &KclExtensions{XKclTypeExpr: "str"}}
+	g = newTestGenContext(t, Markdown)
+	renderTestDoc(t, g, newSpec(port))
+	assert2.Equal(t, []string{"the attribute Server.port: str conflicts with Base.port: int of the base schema"}, g.Warnings)
+
+	g = newTestGenContext(t, Markdown)
+	g.ErrorOnInheritanceConflict = true
+	err := g.renderLocales(newSpec(&KclOpenAPIType{Type: Integer, Format: Int64, Nullable: true}))
+	assert2.EqualError(t, err, "found attributes conflicting with the base schemas:\n"+
+		"  the attribute Server.port: int | None conflicts with Base.port: int of the base schema")
+}
+
+func TestAttributeStyleByOwner(t *testing.T) {
+	source := `schema Server(Base):
+    """A server."""
+    mixin [NameMixin, m.PortMixin]
+    replicas: int
+    image: str
+`
+	assert2.Equal(t, []string{"NameMixin", "m.PortMixin"}, parseSchemaMixins(source, "Server"))
+
+	withOrder := func(schema *KclOpenAPIType, names ...string) *KclOpenAPIType {
+		schema.KclExtensions.XKclAttributeOrder = names
+		return schema
+	}
+	str := func() *KclOpenAPIType { return &KclOpenAPIType{Type: String} }
+	server := withOrder(newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+		"replicas": {Type: Integer, Format: Int64},
+		"image":    str(),
+		"name":     str(),
+		"alias":    str(),
+		"port":     {Type: Integer, Format: Int64},
+		"labels":   str(),
+		"id":       str(),
+	}), "replicas", "image")
+	server.KclExtensions.XKclMixins = []string{SchemaId2Ref("NameMixin"), SchemaId2Ref("PortMixin")}
+	server.KclExtensions.XKclBaseSchema = SchemaId2Ref("Base")
+	nameMixin := withOrder(newTestSchema("NameMixin", "", nil, map[string]*KclOpenAPIType{"name": str(), "alias": str()}), "name", "alias")
+	portMixin := withOrder(newTestSchema("PortMixin", "", nil, map[string]*KclOpenAPIType{"port": {Type: Integer, Format: Int64}}), "port")
+	base := withOrder(newTestSchema("Base", "", nil, map[string]*KclOpenAPIType{"labels": str(), "id": str()}), "labels", "id")
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "NameMixin": nameMixin, "PortMixin": portMixin, "Base": base}}
+
+	g := newTestGenContext(t, Markdown)
+	g.AttributeStyle = ByOwner
+	g.CollapseInherited = true
+	got := renderTestDoc(t, g, spec)["main.md"]
+	row := func(name string, typ string) string {
+		return fmt.Sprintf("|**%s**<br />Optional (may be omitted)|%s|||\n", name, typ)
+	}
+	assert2.Contains(t, got, "### Server\n\n#### Attributes\n\n"+
+		"##### From Server\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n"+row("replicas", "int")+row("image", "str")+"\n"+
+		"##### From NameMixin\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n"+row("name", "str")+row("alias", "str")+"\n"+
+		"##### From PortMixin\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n"+row("port", "int")+"\n"+
+		"##### From Base\n\n| name | type | description | default value |\n| --- | --- | --- | --- |\n"+row("labels", "str")+row("id", "str"))
+	assert2.NotContains(t, got, "Inherited")
+	// the schemas without the mixins and the base schemas are not grouped
+	assert2.Contains(t, got, "### Base\n\n#### Attributes\n\n| name | type | description | default value |\n")
+
+	// the attributes are in one table by default
+	got = renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.NotContains(t, got, "##### From")
+}
+
+func TestRequiredQuickRef(t *testing.T) {
+	deprecated := &KclExtensions{XKclDecorators: XKclDecorators{{Name: "deprecated"}}}
+	server := newTestSchema("Server", "", []string{"port", "name", "host", "_id", "token"}, map[string]*KclOpenAPIType{
+		"port":     {Type: Integer, Format: Int64},
+		"name":     {Type: String},
+		"host":     {Type: String, KclExtensions: deprecated},
+		"replicas": {Type: Integer, Format: Int64},
+		"tags":     {Type: Array, Items: &KclOpenAPIType{Type: String}},
+		"_id":      {Type: String},
+		"token":    {Type: String, Description: "The access token.\n@internal"},
+	})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server}}
+
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.NotContains(t, got, "**Required:**")
+
+	g := newTestGenContext(t, Markdown)
+	g.ShowRequiredQuickRef = true
+	g.IgnoreDeprecated = true
+	g.HideInternal = true
+	got = renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "### Server\n\n**Required:** [name](#server-name) `str`, [port](#server-port) `int`\n\n#### Attributes\n")
+	// the names link to the anchors of the attribute rows
+	assert2.Contains(t, got, `|<a id="server-port"></a>**port** `+"`required`|int|")
+
+	g = newTestGenContext(t, Markdown)
+	g.ShowRequiredQuickRef = true
+	got = renderTestDoc(t, g, spec)["main.md"]
+	assert2.Contains(t, got, "**Required:** [_id](#server-_id) `str`, [host](#server-host) `str`, [name](#server-name) `str`, [port](#server-port) `int`, [token](#server-token) `str`\n")
+
+	g = newTestGenContext(t, Html)
+	g.ShowRequiredQuickRef = true
+	got = renderTestDoc(t, g, spec)["main.html"]
+	assert2.Contains(t, got, `<a href="#server-port">port</a>`)
+	assert2.Contains(t, got, `<a id="server-port"></a><strong>port</strong>`)
+}
+
+func TestFilePerSchema(t *testing.T) {
+	protocol := func() *KclOpenAPIType {
+		return &KclOpenAPIType{
+			Type: Object,
+			KclExtensions: &KclExtensions{
+				XKclUnionTypes: []*KclOpenAPIType{
+					{Type: String, ReadOnly: true, Default: `"TCP"`, Enum: []string{`"TCP"`}},
+					{Type: String, ReadOnly: true, Default: `"UDP"`, Enum: []string{`"UDP"`}},
+				},
+				XKclTypeExpr: "types.Protocol",
+				XKclEnum:     "types.Protocol",
+			},
+		}
+	}
+	server := newTestSchema("Server", "", []string{"protocol"}, map[string]*KclOpenAPIType{"protocol": protocol()})
+	server.KclExtensions.XKclModelType.Import.Package = "models"
+	service := newTestSchema("Service", "", nil, map[string]*KclOpenAPIType{
+		"protocol": protocol(),
+		"servers":  {Type: Array, Items: &KclOpenAPIType{Ref: "#/definitions/models.Server"}},
+	})
+	service.KclExtensions.XKclModelType.Import.Package = "app.web"
+	app := newTestSchema("App", "", nil, map[string]*KclOpenAPIType{"name": {Type: String}})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{
+		"App": app, "models.Server": server, "app.web.Service": service,
+	}}
+
+	g := newTestGenContext(t, Markdown)
+	g.FilePerSchema = true
+	files := renderTestDoc(t, g, spec)
+	assert2.Equal(t, []string{"App.md", "app.web.Service.md", "main.md", "models.Server.md", "types.Protocol.md"}, getSortedKeys(files))
+	assert2.Equal(t, "# main\n\n## Index\n\n### Schemas\n\n- [App](App.md)\n- app\n  - web\n    - [Service](app.web.Service.md)\n- models\n  - [Server](models.Server.md)\n\n### Enums\n\n- types\n  - [Protocol](types.Protocol.md)\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n", files["main.md"])
+	assert2.Equal(t, "# Protocol\n\nPackage: types\n\n## Values\n\n- `\"TCP\"`\n- `\"UDP\"`\n\n## Used By\n\n- [Service](app.web.Service.md) `protocol`\n- [Server](models.Server.md) `protocol`\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n", files["types.Protocol.md"])
+	// the attributes link to the enum page and the schema pages
+	assert2.Contains(t, files["models.Server.md"], "# Server\n")
+	assert2.Contains(t, files["models.Server.md"], "## Attributes\n")
+	assert2.Contains(t, files["models.Server.md"], "|**protocol** `required`|[Protocol](types.Protocol.md) (`types.Protocol`)|")
+	assert2.Contains(t, files["app.web.Service.md"], "|**protocol**<br />Optional (may be omitted)|[Protocol](types.Protocol.md) (`types.Protocol`)|")
+	assert2.Contains(t, files["app.web.Service.md"], "|**servers**<br />Optional (may be omitted)|[[Server](models.Server.md)]<br />")
+
+	g = newTestGenContext(t, Html)
+	g.FilePerSchema = true
+	files = renderTestDoc(t, g, spec)
+	assert2.Contains(t, files["main.html"], `<a href="types.Protocol.html">Protocol</a>`)
+	assert2.Contains(t, files["models.Server.html"], `<a href="types.Protocol.html">Protocol</a>`)
+
+	// the enums are not linked in the single page
+	got := renderTestDoc(t, newTestGenContext(t, Markdown), spec)["main.md"]
+	assert2.NotContains(t, got, "types.Protocol.md")
+	assert2.Contains(t, got, "|**servers**<br />Optional (may be omitted)|[[Server](#server)]<br />")
+
+	assert2.Equal(t, "types.Protocol", typeAliasName("types.Protocol | None"))
+	assert2.Equal(t, "", typeAliasName(`"TCP" | "UDP"`))
+}
+
+func TestAnnotationsToFrontMatter(t *testing.T) {
+	server := newTestSchema("Server", "A server.\n@category networking\n@owner team-a\n@owner team-b\n@since 1.2", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+	client := newTestSchema("Client", "A client.\n@category networking", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+	plain := newTestSchema("Plain", "A plain schema.", nil, map[string]*KclOpenAPIType{"host": {Type: String}})
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Client": client, "Plain": plain}}
+
+	g := newTestGenContext(t, Markdown)
+	g.FilePerSchema = true
+	g.AnnotationsToFrontMatter = []string{"category", "owner", "since", "status"}
+	files := renderTestDoc(t, g, spec)
+	assert2.True(t, strings.HasPrefix(files["Server.md"], "---\ncategory: networking\nowner:\n- team-a\n- team-b\nsince: \"1.2\"\n---\n\n# Server\n\nA server.\n"))
+	// the absent tags are omitted
+	assert2.True(t, strings.HasPrefix(files["Client.md"], "---\ncategory: networking\n---\n\n# Client\n"))
+	assert2.True(t, strings.HasPrefix(files["Plain.md"], "# Plain\n"))
+	assert2.True(t, strings.HasPrefix(files["main.md"], "# main\n"))
+
+	// the html pages have no front matter
+	g = newTestGenContext(t, Html)
+	g.FilePerSchema = true
+	g.AnnotationsToFrontMatter = []string{"category"}
+	assert2.NotContains(t, renderTestDoc(t, g, spec)["Server.html"], "category: networking")
+}
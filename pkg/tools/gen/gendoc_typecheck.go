@@ -0,0 +1,39 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkDefaultTypes checks the literal defaults of the schema attributes against the declared attribute types. The
+// mismatches are reported as warnings, or fail the generation if ErrorOnTypeMismatch is set. The defaults which are
+// not the literals, such as the expressions and the schema instances, are not checked.
+func (g *GenContext) checkDefaultTypes(spec *SwaggerV2Spec) error {
+	var mismatches []string
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		for _, name := range getSortedKeys(schema.Properties) {
+			prop := schema.Properties[name]
+			if prop.Default == "" {
+				continue
+			}
+			value, ok := kclLiteralToJson(prop.Default)
+			if !ok {
+				continue
+			}
+			if errs := validateInstance(spec, prop, value, id+"."+name); len(errs) > 0 {
+				mismatches = append(mismatches, fmt.Sprintf("the default value %s of %s", prop.Default, strings.Join(errs, "; ")))
+			}
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	if g.ErrorOnTypeMismatch {
+		return fmt.Errorf("found defaults which do not match the attribute types:\n  %s", strings.Join(mismatches, "\n  "))
+	}
+	for _, m := range mismatches {
+		g.warnf("%s", m)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package gen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// qualifierRegexp matches the package qualifiers of the schema names such as `core.` in `core.PodSpec`, which the
+// type labels may omit
+var qualifierRegexp = regexp.MustCompile(`\b[a-zA-Z_][a-zA-Z0-9_]*\.`)
+
+// typeExprMarkerRegexp matches the markers of the type expressions in the html docs, which survive the markdown
+// conversion and are replaced with the hover titles. The expression is base64url encoded in the marker
+var typeExprMarkerRegexp = regexp.MustCompile(`⟦kcl-type:([A-Za-z0-9_=-]*)⟧(.*?)⟦/kcl-type⟧`)
+
+// normalizeTypeExpr removes the spaces and the package qualifiers, and unifies the quotes of the type expression to
+// compare it with the type label
+func normalizeTypeExpr(expr string) string {
+	expr = strings.Join(strings.Fields(expr), "")
+	expr = strings.ReplaceAll(expr, "'", `"`)
+	return qualifierRegexp.ReplaceAllString(expr, "")
+}
+
+// typeExpression returns the type expression of the attribute as authored, or empty if the type label already
+// renders the same type, such as `{str: str}` for `{str:str}`
+func typeExpression(tpe *KclOpenAPIType) string {
+	if tpe.KclExtensions == nil || tpe.KclExtensions.XKclTypeExpr == "" || tpe.isAnyType() {
+		return ""
+	}
+	expr := tpe.KclExtensions.XKclTypeExpr
+	if normalizeTypeExpr(expr) == normalizeTypeExpr(tpe.GetKclTypeName(false, false, false)) {
+		return ""
+	}
+	return expr
+}
+
+// withTypeExpression renders the type label with the type expression as authored if it differs from the label. The
+// markdown docs render the expression in parentheses, and the html docs render it as the hover title of the label
+func (g *GenContext) withTypeExpression(tpe *KclOpenAPIType, label string) string {
+	expr := typeExpression(tpe)
+	if expr == "" {
+		return label
+	}
+	if g.Format == Html {
+		return fmt.Sprintf("⟦kcl-type:%s⟧%s⟦/kcl-type⟧", base64.URLEncoding.EncodeToString([]byte(expr)), label)
+	}
+	return fmt.Sprintf("%s (`%s`)", label, strings.ReplaceAll(expr, "|", "\\|"))
+}
+
+// addTypeExpressionTitles replaces the type expression markers in the html doc with the spans of the type labels
+// whose titles are the type expressions
+func addTypeExpressionTitles(content []byte) []byte {
+	return typeExprMarkerRegexp.ReplaceAllFunc(content, func(marker []byte) []byte {
+		m := typeExprMarkerRegexp.FindSubmatch(marker)
+		expr, err := base64.URLEncoding.DecodeString(string(m[1]))
+		if err != nil {
+			return m[2]
+		}
+		return []byte(fmt.Sprintf(`<span class="kcl-type" title="%s">%s</span>`, html.EscapeString(string(expr)), m[2]))
+	})
+}
@@ -0,0 +1,313 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	kclModFile         = "kcl.mod"
+	workspaceIndexName = "index"
+)
+
+// kclModPackage is the `[package]` section of kcl.mod used to discover the workspace packages
+type kclModPackage struct {
+	Name    string   `toml:"name"`
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+}
+
+// workspaceModule is a KCL module discovered in the workspace
+type workspaceModule struct {
+	Path string // absolute path to the module root
+	Rel  string // slash-separated path relative to the workspace root, "." for the workspace root module
+}
+
+// workspaceIndexEntry is an entry of the workspace index
+type workspaceIndexEntry struct {
+	Name        string
+	Version     string
+	Description string
+	Link        string
+	Deprecation *packageDeprecation
+}
+
+// readKclModPackage reads the `[package]` section of the kcl.mod in the directory
+func readKclModPackage(dir string) (*kclModPackage, error) {
+	var mod struct {
+		Package kclModPackage `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(dir, kclModFile), &mod); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in %s: %s", kclModFile, dir, err)
+	}
+	return &mod.Package, nil
+}
+
+// matchWorkspacePattern checks if the slash-separated relative path or one of its parent directories matches the
+// include/exclude pattern of kcl.mod
+func matchWorkspacePattern(pattern string, rel string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+	for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excludes checks if the path relative to the module root is excluded by the module
+func (m *kclModPackage) excludes(rel string) bool {
+	for _, pattern := range m.Exclude {
+		if matchWorkspacePattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// includes checks if the path relative to the module root is included by the module
+func (m *kclModPackage) includes(rel string) bool {
+	if len(m.Include) == 0 {
+		return true
+	}
+	for _, pattern := range m.Include {
+		if matchWorkspacePattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverWorkspaceModules discovers the KCL modules under the kcl.mod-rooted workspace, which are the root module
+// and the nested modules included by the include/exclude settings of their parent modules. Hidden directories are
+// skipped, and the symbolic links are followed only if they resolve to a directory inside the workspace which is
+// not visited yet, so the links out of the workspace and the link cycles are ignored.
+func discoverWorkspaceModules(root string) ([]workspaceModule, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace root %s: %s", root, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, kclModFile)); err != nil {
+		return nil, fmt.Errorf("invalid workspace root %s: %s not found", root, kclModFile)
+	}
+	var modules []workspaceModule
+	visited := map[string]bool{}
+	// modRel is the path of the enclosing module relative to the workspace root
+	var walk func(dir string, rel string, mod *kclModPackage, modRel string) error
+	walk = func(dir string, rel string, mod *kclModPackage, modRel string) error {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			// skip the broken links
+			return nil
+		}
+		if relToRoot, err := filepath.Rel(root, realDir); err != nil || strings.HasPrefix(relToRoot, "..") {
+			// skip the links out of the workspace
+			return nil
+		}
+		if visited[realDir] {
+			return nil
+		}
+		visited[realDir] = true
+
+		if _, err := os.Stat(filepath.Join(dir, kclModFile)); err == nil {
+			if mod == nil || mod.includes(relTo(modRel, rel)) {
+				modules = append(modules, workspaceModule{Path: dir, Rel: rel})
+			}
+			nested, err := readKclModPackage(dir)
+			if err != nil {
+				return err
+			}
+			mod, modRel = nested, rel
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			child := filepath.Join(dir, entry.Name())
+			if info, err := os.Stat(child); err != nil || !info.IsDir() {
+				continue
+			}
+			childRel := path.Join(rel, entry.Name())
+			if mod.excludes(relTo(modRel, childRel)) {
+				continue
+			}
+			if err := walk(child, childRel, mod, modRel); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root, ".", nil, "."); err != nil {
+		return nil, err
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Rel < modules[j].Rel
+	})
+	return modules, nil
+}
+
+// relTo returns the slash-separated path relative to the base path
+func relTo(base string, rel string) string {
+	if base == "." {
+		return rel
+	}
+	return strings.TrimPrefix(rel, base+"/")
+}
+
+// genWorkspaceDoc generates the docs of all the modules in the workspace into the directories mirroring the
+// workspace layout, and an index of all the modules
+func (g *GenContext) genWorkspaceDoc() error {
+	if g.TechDocs {
+		return fmt.Errorf("the TechDocs layout does not support generating docs for a workspace")
+	}
+	modules, err := discoverWorkspaceModules(g.WorkspaceRoot)
+	if err != nil {
+		return err
+	}
+	g.packageDocLinks = map[string]string{}
+	for _, m := range modules {
+		mod, err := readKclModPackage(m.Path)
+		if err != nil {
+			return err
+		}
+		if mod.Name != "" {
+			g.packageDocLinks[mod.Name] = path.Join(m.Rel, fmt.Sprintf("%s.%s", mod.Name, g.Format))
+		}
+	}
+	options, err := g.docCacheOptions()
+	if err != nil {
+		return err
+	}
+	cache := readDocCache(g.Target, g.Format, options)
+	var entries []workspaceIndexEntry
+	for _, m := range modules {
+		render := func(p *docCachePackage) error {
+			return g.renderWorkspaceModule(m, p)
+		}
+		p := &docCachePackage{}
+		if g.Incremental {
+			if _, err := cache.refresh(g, g.Target, m.Rel, m.Path, nestedModules(modules, m), render); err != nil {
+				return err
+			}
+			p = cache.Packages[m.Rel]
+		} else if err := render(p); err != nil {
+			return err
+		}
+		if p.Deprecation != nil && g.IgnoreDeprecated {
+			continue
+		}
+		entries = append(entries, workspaceIndexEntry{
+			Name:        p.Name,
+			Version:     p.Version,
+			Description: p.Description,
+			Link:        path.Join(m.Rel, fmt.Sprintf("%s.%s", p.Name, g.Format)),
+			Deprecation: p.Deprecation,
+		})
+	}
+	if g.Incremental {
+		if err := cache.write(g.Target); err != nil {
+			return err
+		}
+	}
+	return g.renderWorkspaceIndex(entries)
+}
+
+// renderWorkspaceModule renders the docs of the workspace module, and records its index entry in the manifest entry
+func (g *GenContext) renderWorkspaceModule(m workspaceModule, p *docCachePackage) error {
+	var spec *SwaggerV2Spec
+	var err error
+	if g.Incremental {
+		spec, err = g.exportCachedSpec(m.Path, p.Hash)
+	} else {
+		spec, err = exportSwaggerV2Spec(m.Path, g.logger())
+	}
+	if err != nil {
+		return err
+	}
+	module, err := g.clone()
+	if err != nil {
+		return err
+	}
+	module.PackagePath = m.Path
+	module.Target = filepath.Join(g.Target, filepath.FromSlash(m.Rel))
+	module.packageDocDir = m.Rel
+	if err := module.renderLocales(spec); err != nil {
+		return fmt.Errorf("render doc of %s failed: %s", m.Path, err)
+	}
+	g.Warnings = append(g.Warnings, module.Warnings...)
+	g.UnresolvedLinks = append(g.UnresolvedLinks, module.UnresolvedLinks...)
+	p.Name = spec.Info.Title
+	if p.Name == "" {
+		p.Name = "main"
+	}
+	p.Version = spec.Info.Version
+	p.Description = firstSentence(docText(spec.Info.Description))
+	p.Deprecation = parsePackageDeprecation(spec.Info.Description)
+	return nil
+}
+
+// nestedModules returns the paths of the workspace modules nested in the module relative to it, whose sources and
+// docs belong to the nested modules
+func nestedModules(modules []workspaceModule, m workspaceModule) []string {
+	var nested []string
+	for _, other := range modules {
+		if other.Rel == m.Rel {
+			continue
+		}
+		if m.Rel == "." {
+			nested = append(nested, other.Rel)
+		} else if strings.HasPrefix(other.Rel, m.Rel+"/") {
+			nested = append(nested, strings.TrimPrefix(other.Rel, m.Rel+"/"))
+		}
+	}
+	return nested
+}
+
+// renderWorkspaceIndex writes the index of the workspace modules for the markdown and html formats
+func (g *GenContext) renderWorkspaceIndex(entries []workspaceIndexEntry) error {
+	if g.Format != Markdown && g.Format != Html {
+		g.logger().Info("skipping the workspace index, which is only rendered for the markdown and html docs", "format", g.Format)
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Packages\n\n| name | version | description |\n| --- | --- | --- |\n")
+	for _, e := range entries {
+		name, description := fmt.Sprintf("[%s](%s)", e.Name, e.Link), e.Description
+		if e.Deprecation != nil {
+			// the deprecated packages are struck through with the deprecation banner
+			name = fmt.Sprintf("~~%s~~", name)
+			description = strings.TrimSpace(e.Deprecation.banner(g.packageDocLinks[e.Deprecation.Successor]) + " " + description)
+		}
+		fmt.Fprintf(&buf, "|%s|%s|%s|\n", name, e.Version, strings.Replace(description, "|", "\\|", -1))
+	}
+	buf.WriteString("<!-- Auto generated by kcl-doc tool, please do not edit. -->\n")
+	content := buf.Bytes()
+	if g.Format == Html {
+		var htmlBuf bytes.Buffer
+		// the deprecated packages are struck through
+		md := goldmark.New(goldmark.WithExtensions(extension.Strikethrough))
+		if err := md.Convert(content, &htmlBuf); err != nil {
+			return err
+		}
+		content = htmlBuf.Bytes()
+	}
+	docFileName := fmt.Sprintf("%s.%s", workspaceIndexName, g.Format)
+	return g.writeOutput(g.Target, docFileName, g.withToolHeader(docFileName, content))
+}
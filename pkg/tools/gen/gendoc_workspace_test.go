@@ -0,0 +1,118 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverWorkspaceModules(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(rel string, content string) {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("kcl.mod", "[package]\nname = \"root\"\nexclude = [\"vendor\", \"legacy*\"]\n")
+	writeFile("main.k", "")
+	writeFile("apps/web/kcl.mod", "[package]\nname = \"web\"\n")
+	writeFile("libs/kcl.mod", "[package]\nname = \"libs\"\ninclude = [\"core\"]\n")
+	writeFile("libs/core/kcl.mod", "[package]\nname = \"core\"\n")
+	writeFile("libs/extra/kcl.mod", "[package]\nname = \"extra\"\n")
+	writeFile("vendor/dep/kcl.mod", "[package]\nname = \"dep\"\n")
+	writeFile("legacy-v1/kcl.mod", "[package]\nname = \"legacy\"\n")
+	writeFile(".git/kcl.mod", "")
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "kcl.mod"), []byte("[package]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a link cycle, a link to a module in the workspace and a link out of the workspace
+	if err := os.Symlink(root, filepath.Join(root, "apps", "cycle")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "apps", "web"), filepath.Join(root, "web-link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "outside")); err != nil {
+		t.Fatal(err)
+	}
+
+	modules, err := discoverWorkspaceModules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range modules {
+		got = append(got, m.Rel)
+	}
+	assert2.Equal(t, []string{".", "apps/web", "libs", "libs/core"}, got)
+
+	_, err = discoverWorkspaceModules(filepath.Join(root, "apps"))
+	assert2.Error(t, err)
+}
+
+func TestRenderWorkspaceIndex(t *testing.T) {
+	g := newTestGenContext(t, Markdown)
+	err := g.renderWorkspaceIndex([]workspaceIndexEntry{
+		{Name: "root", Version: "0.1.0", Description: "The root module.", Link: "root.md"},
+		{Name: "web", Version: "0.2.0", Link: "apps/web/web.md"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.Target, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, "# Packages\n\n| name | version | description |\n| --- | --- | --- |\n|[root](root.md)|0.1.0|The root module.|\n|[web](apps/web/web.md)|0.2.0||\n<!-- Auto generated by kcl-doc tool, please do not edit. -->\n", string(content))
+
+	// the index is recorded in memory when rendering from the sources
+	g = newTestGenContext(t, Markdown)
+	g.memoryOutputs = &memoryOutputs{root: g.Target, files: map[string][]byte{}}
+	assert2.NoError(t, g.renderWorkspaceIndex([]workspaceIndexEntry{{Name: "root", Link: "root.md"}}))
+	assert2.Contains(t, string(g.memoryOutputs.files["index.md"]), "|[root](root.md)||")
+	_, err = os.Stat(g.Target)
+	assert2.True(t, os.IsNotExist(err))
+
+	// the data formats have no index
+	g = newTestGenContext(t, OpenAPI)
+	assert2.NoError(t, g.renderWorkspaceIndex([]workspaceIndexEntry{{Name: "root", Link: "root.json"}}))
+	_, err = os.Stat(g.Target)
+	assert2.True(t, os.IsNotExist(err))
+}
+
+func TestRenderWorkspaceIndexDeprecated(t *testing.T) {
+	g := newTestGenContext(t, Markdown)
+	g.packageDocLinks = map[string]string{"models": "libs/models/models.md"}
+	err := g.renderWorkspaceIndex([]workspaceIndexEntry{
+		{Name: "legacy", Version: "0.1.0", Description: "The legacy models.", Link: "libs/legacy/legacy.md", Deprecation: &packageDeprecation{Successor: "models"}},
+		{Name: "models", Version: "0.2.0", Link: "libs/models/models.md"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.Target, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Contains(t, string(content), "|~~[legacy](libs/legacy/legacy.md)~~|0.1.0|**Deprecated**: this package is deprecated. Use [models](libs/models/models.md) instead. The legacy models.|\n|[models](libs/models/models.md)|0.2.0||\n")
+
+	g = newTestGenContext(t, Html)
+	err = g.renderWorkspaceIndex([]workspaceIndexEntry{
+		{Name: "legacy", Link: "legacy.html", Deprecation: &packageDeprecation{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(filepath.Join(g.Target, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Contains(t, string(content), `<del><a href="legacy.html">legacy</a></del>`)
+}
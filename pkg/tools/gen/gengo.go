@@ -17,6 +17,14 @@ type GenGoOptions struct {
 	Package  string
 	AnyType  string
 	UseValue bool
+	// EmitConstructors defines whether to generate a constructor applying the KCL defaults, the functional option
+	// setters of the optional attributes and a Validate method for each schema
+	EmitConstructors bool
+	// EmitTestFixtures defines whether to generate the sample instances of each schema covering the defaults, the
+	// bounds and the enum members, and the instances violating each constraint, as the seeds of the tests. The
+	// fixture functions are written after the struct of each schema into the same Go output, so they compile with
+	// the structs without any import
+	EmitTestFixtures bool
 }
 
 // GenGo translate kcl schema type to go struct.
@@ -26,6 +34,8 @@ func GenGo(w io.Writer, filename string, src interface{}, opts *GenGoOptions) er
 
 type goGenerator struct {
 	opts *GenGoOptions
+	// source is the KCL source code of the types, from which the check expressions are parsed
+	source string
 }
 
 func newGoGenerator(opts *GenGoOptions) *goGenerator {
@@ -44,6 +54,7 @@ func (g *goGenerator) GenFromSource(w io.Writer, filename string, src interface{
 	if err != nil {
 		return err
 	}
+	g.source = string(code)
 
 	types, err := kcl.GetSchemaType(filename, string(code), "")
 	if err != nil {
@@ -56,10 +67,25 @@ func (g *goGenerator) GenFromSource(w io.Writer, filename string, src interface{
 }
 
 func (g *goGenerator) GenFromTypes(w io.Writer, types ...*pb.KclType) {
+	if g.opts.EmitConstructors {
+		fmt.Fprint(w, goRequiredErrorType)
+	}
+	schemas := map[string]*pb.KclType{}
+	for _, typ := range types {
+		if typ.Type == typSchema {
+			schemas[typ.SchemaName] = typ
+		}
+	}
 	for _, typ := range types {
 		switch typ.Type {
 		case typSchema:
 			g.GenSchema(w, typ)
+			if g.opts.EmitConstructors {
+				g.GenConstructors(w, typ)
+			}
+			if g.opts.EmitTestFixtures {
+				g.GenTestFixtures(w, typ, schemas)
+			}
 		}
 	}
 }
@@ -0,0 +1,209 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+	pb "kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+// goRequiredErrorType is the error type returned by the generated Validate methods, which is emitted once before the
+// schemas so the generated code does not need any import
+const goRequiredErrorType = `
+// KclRequiredError reports a required attribute which is not set.
+type KclRequiredError struct {
+    Schema    string
+    Attribute string
+}
+
+func (e *KclRequiredError) Error() string {
+    return e.Schema + "." + e.Attribute + " is required"
+}
+`
+
+// GenConstructors generates the constructor of the schema struct, which takes the required attributes without
+// defaults and applies the attribute defaults, the functional option setters of the other attributes, and the
+// Validate method which checks the required attributes which may be nil are set.
+func (g *goGenerator) GenConstructors(w io.Writer, typ *pb.KclType) {
+	assert(typ.Type == typSchema)
+
+	var (
+		name       = goExportedName(typ.SchemaName)
+		optionType = name + "Option"
+		required   = map[string]bool{}
+		params     []string
+		defaults   []string
+		options    []string
+	)
+	for _, r := range typ.Required {
+		required[r] = true
+	}
+	for _, fieldName := range getSortedFieldNames(typ.Properties) {
+		fieldType := typ.Properties[fieldName]
+		if lit, ok := g.goDefaultLiteral(fieldType); ok {
+			defaults = append(defaults, fmt.Sprintf("%s: %s,", fieldName, lit))
+		} else if required[fieldName] {
+			params = append(params, fieldName)
+			continue
+		}
+		options = append(options, fieldName)
+	}
+
+	paramDefines := make([]string, 0, len(params)+1)
+	for _, p := range params {
+		paramDefines = append(paramDefines, fmt.Sprintf("%s %s", goParamName(p), g.GetTypeName(typ.Properties[p])))
+	}
+	paramDefines = append(paramDefines, fmt.Sprintf("opts ...%s", optionType))
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// %s sets an optional attribute of %s.\n", optionType, typ.SchemaName)
+	fmt.Fprintf(w, "type %s func(*%s)\n", optionType, typ.SchemaName)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// New%s creates a %s with the required attributes and the defaults applied.\n", name, typ.SchemaName)
+	fmt.Fprintf(w, "func New%s(%s) *%s {\n", name, strings.Join(paramDefines, ", "), typ.SchemaName)
+	fmt.Fprintf(w, "    obj := &%s{\n", typ.SchemaName)
+	for _, d := range defaults {
+		fmt.Fprintf(w, "        %s\n", d)
+	}
+	fmt.Fprintf(w, "    }\n")
+	for _, p := range params {
+		fmt.Fprintf(w, "    obj.%s = %s\n", p, goParamName(p))
+	}
+	fmt.Fprintf(w, "    for _, opt := range opts {\n        opt(obj)\n    }\n")
+	fmt.Fprintf(w, "    return obj\n}\n")
+
+	for _, fieldName := range options {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "// With%s%s sets the %s attribute of %s.\n", name, goExportedName(fieldName), fieldName, typ.SchemaName)
+		fmt.Fprintf(w, "func With%s%s(v %s) %s {\n", name, goExportedName(fieldName), g.GetTypeName(typ.Properties[fieldName]), optionType)
+		fmt.Fprintf(w, "    return func(obj *%s) {\n        obj.%s = v\n    }\n}\n", typ.SchemaName, fieldName)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// Validate checks the required attributes of %s are set.\n", typ.SchemaName)
+	fmt.Fprintf(w, "func (obj *%s) Validate() error {\n", typ.SchemaName)
+	for _, fieldName := range getSortedFieldNames(typ.Properties) {
+		if !required[fieldName] || !g.isNilable(typ.Properties[fieldName]) {
+			continue
+		}
+		fmt.Fprintf(w, "    if obj.%s == nil {\n", fieldName)
+		fmt.Fprintf(w, "        return &KclRequiredError{Schema: %q, Attribute: %q}\n", typ.SchemaName, fieldName)
+		fmt.Fprintf(w, "    }\n")
+	}
+	fmt.Fprintf(w, "    return nil\n}\n")
+}
+
+// isNilable returns whether the Go type of the KCL type may be nil
+func (g *goGenerator) isNilable(typ *pb.KclType) bool {
+	name := g.GetTypeName(typ)
+	return strings.HasPrefix(name, "*") || strings.HasPrefix(name, "[]") || strings.HasPrefix(name, "map[") || name == g.opts.AnyType
+}
+
+// goDefaultLiteral translates the KCL default of the attribute to the Go literal of its field type. The defaults
+// which are not the literals, the None defaults, the unit literals and the schema instances are not translated
+func (g *goGenerator) goDefaultLiteral(typ *pb.KclType) (string, bool) {
+	if typ.Default == "" || isUnitLiteral(typ.Default) {
+		return "", false
+	}
+	value, ok := kclDefaultValue(typ.Default)
+	if !ok {
+		return "", false
+	}
+	return g.goLiteral(typ, value)
+}
+
+// goLiteral renders the value as the Go literal of the KCL type
+func (g *goGenerator) goLiteral(typ *pb.KclType, value interface{}) (string, bool) {
+	basicTyp := typ.Type
+	if isLit, litTyp, _ := IsLitType(typ); isLit {
+		basicTyp = litTyp
+	}
+	switch basicTyp {
+	case typStr:
+		if s, ok := value.(string); ok {
+			return strconv.Quote(s), true
+		}
+	case typInt:
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return fmt.Sprint(v), true
+		case float64:
+			if v == float64(int64(v)) {
+				return strconv.FormatInt(int64(v), 10), true
+			}
+		}
+	case typFloat:
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return fmt.Sprint(v), true
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), true
+		}
+	case typBool:
+		if b, ok := value.(bool); ok {
+			return strconv.FormatBool(b), true
+		}
+	case typList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", false
+		}
+		elems := make([]string, len(items))
+		for i, item := range items {
+			if elems[i], ok = g.goLiteral(typ.Item, item); !ok {
+				return "", false
+			}
+		}
+		return fmt.Sprintf("%s{%s}", g.GetTypeName(typ), strings.Join(elems, ", ")), true
+	case typDict:
+		entries, ok := value.(yaml.MapSlice)
+		if !ok {
+			return "", false
+		}
+		elems := make([]string, len(entries))
+		for i, entry := range entries {
+			key, ok := g.goLiteral(typ.Key, entry.Key)
+			if !ok {
+				return "", false
+			}
+			val, ok := g.goLiteral(typ.Item, entry.Value)
+			if !ok {
+				return "", false
+			}
+			elems[i] = fmt.Sprintf("%s: %s", key, val)
+		}
+		return fmt.Sprintf("%s{%s}", g.GetTypeName(typ), strings.Join(elems, ", ")), true
+	case typUnion:
+		for _, t := range typ.UnionTypes {
+			if lit, ok := g.goLiteral(t, value); ok {
+				return lit, true
+			}
+		}
+	}
+	return "", false
+}
+
+// goExportedName returns the name with the first letter in upper case
+func goExportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// goParamName returns the constructor parameter name of the attribute, which must not be a Go keyword or clash with
+// the names used in the constructor
+func goParamName(name string) string {
+	if token.IsKeyword(name) || name == "opts" || name == "obj" || name == "opt" {
+		return name + "_"
+	}
+	return name
+}
@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+	pb "kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+func TestGenGoConstructors(t *testing.T) {
+	strType := &pb.KclType{Type: typStr}
+	person := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Person",
+		Properties: map[string]*pb.KclType{
+			"name":    {Type: typStr, Default: `"kcl"`, Line: 1},
+			"age":     {Type: typInt, Default: "2", Line: 2},
+			"ratio":   {Type: typFloat, Default: "0.5", Line: 3},
+			"enabled": {Type: typBool, Default: "True", Line: 4},
+			"labels":  {Type: typDict, Key: strType, Item: strType, Default: `{"app": "web"}`, Line: 5},
+			"tags":    {Type: typList, Item: strType, Default: `["a", "b"]`, Line: 6},
+			"friends": {Type: typList, Item: strType, Default: "None", Line: 7},
+			"size":    {Type: typNumberMultiplier, Default: "2M", Line: 8},
+		},
+		Required: []string{"name", "age"},
+	}
+	company := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Company",
+		Properties: map[string]*pb.KclType{
+			"name":      {Type: typStr, Line: 1},
+			"persons":   {Type: typSchema, SchemaName: "Person", Line: 2},
+			"employees": {Type: typList, Item: &pb.KclType{Type: typSchema, SchemaName: "Person"}, Line: 3},
+		},
+		Required: []string{"name", "persons", "employees"},
+	}
+
+	var buf bytes.Buffer
+	newGoGenerator(&GenGoOptions{AnyType: goAnyType, EmitConstructors: true}).GenFromTypes(&buf, person, company)
+	code := buf.String()
+	assert2.Contains(t, code, `func NewPerson(opts ...PersonOption) *Person {
+    obj := &Person{
+        name: "kcl",
+        age: 2,
+        ratio: 0.5,
+        enabled: true,
+        labels: map[string]string{"app": "web"},
+        tags: []string{"a", "b"},
+    }
+`)
+	assert2.Contains(t, code, "func NewCompany(name string, persons *Person, employees []*Person, opts ...CompanyOption) *Company {\n")
+	assert2.Contains(t, code, "func WithPersonSize(v int) PersonOption {\n")
+	assert2.NotContains(t, code, "WithCompanyName")
+
+	buf.Reset()
+	newGoGenerator(&GenGoOptions{AnyType: goAnyType}).GenFromTypes(&buf, person)
+	assert2.NotContains(t, buf.String(), "NewPerson")
+
+	assert2.Equal(t, "type_", goParamName("type"))
+	assert2.Equal(t, "opts_", goParamName("opts"))
+	assert2.Equal(t, "Employee", goExportedName("employee"))
+
+	// run the generated constructors
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+	dir := t.TempDir()
+	main := "package main\n\nimport \"fmt\"\n" + code + `
+func main() {
+    p := NewPerson(WithPersonAge(3), WithPersonSize(1024))
+    c := NewCompany("kcl", nil, []*Person{p})
+    fmt.Println(p.name, p.age, p.ratio, p.enabled, p.labels["app"], len(p.tags), p.friends == nil, p.size)
+    fmt.Println(c.Validate())
+    c.persons = p
+    fmt.Println(c.Validate())
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run the generated code: %s\n%s", err, out)
+	}
+	assert2.Equal(t, "kcl 3 0.5 true web 2 true 1024\nCompany.persons is required\n<nil>\n", string(out))
+}
@@ -0,0 +1,345 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+// goFieldConstraints is the bounds of a schema attribute derived from the schema check expressions
+type goFieldConstraints struct {
+	Min, Max       *int // bounds of the int and float values
+	MinLen, MaxLen *int // bounds of the lengths of the strings and lists
+}
+
+// goFixture is a sample instance of a schema, which overrides an attribute of the base instance
+type goFixture struct {
+	Comment string
+	Field   string
+	Value   string
+}
+
+// schemaFieldConstraints derives the bounds of the attributes from the unconditional comparisons in the schema check
+// expressions, such as `0 <= age <= 120` and `len(name) >= 1`
+func schemaFieldConstraints(checks []*XKclCheck, typ *pb.KclType) map[string]*goFieldConstraints {
+	constraints := map[string]*goFieldConstraints{}
+	for _, check := range checks {
+		if check.Condition != "" {
+			continue
+		}
+		for _, c := range parseComparisons(check.Expr) {
+			for name, prop := range typ.Properties {
+				fc := constraints[name]
+				if fc == nil {
+					fc = &goFieldConstraints{}
+				}
+				switch goBasicType(prop) {
+				case typInt, typFloat, typNumberMultiplier:
+					min, max := c.intBounds(name)
+					fc.Min, fc.Max = mergeBound(fc.Min, min), mergeBound(fc.Max, max)
+				case typStr, typList:
+					min, max := c.intBounds("len(" + name + ")")
+					fc.MinLen, fc.MaxLen = mergeBound(fc.MinLen, min), mergeBound(fc.MaxLen, max)
+				}
+				if fc.Min != nil || fc.Max != nil || fc.MinLen != nil || fc.MaxLen != nil {
+					constraints[name] = fc
+				}
+			}
+		}
+	}
+	return constraints
+}
+
+// goBasicType returns the type of the literal types, or the type itself
+func goBasicType(typ *pb.KclType) string {
+	if isLit, litTyp, _ := IsLitType(typ); isLit {
+		return litTyp
+	}
+	return typ.Type
+}
+
+// GenTestFixtures generates the functions returning the sample instances of the schema struct, which cover the
+// defaults, the bounds and the enum members of the attributes as the seeds of the fuzz and property tests, and the
+// instances violating each constraint for the negative tests. The bounds are derived from the schema check block of
+// the source, and the schemas are the generated schemas by name whose fixtures may be referenced. The attributes of
+// the schemas referencing the schema back, such as `next: Node` of `Node`, are not sampled, so the fixtures do not
+// recurse.
+func (g *goGenerator) GenTestFixtures(w io.Writer, typ *pb.KclType, schemas map[string]*pb.KclType) {
+	assert(typ.Type == typSchema)
+
+	referable := map[string]bool{}
+	for schemaName, schema := range schemas {
+		referable[schemaName] = !reachesSchema(schema, typ.SchemaName, schemas, map[string]bool{})
+	}
+	var (
+		name        = goExportedName(typ.SchemaName)
+		constraints = schemaFieldConstraints(parseSchemaChecks(g.source, typ.SchemaName), typ)
+		fieldNames  = getSortedFieldNames(typ.Properties)
+		required    = map[string]bool{}
+		base        = map[string]string{}
+		valid       = []goFixture{{Comment: "the defaults"}}
+		invalid     []goFixture
+	)
+	for _, r := range typ.Required {
+		required[r] = true
+	}
+	for _, fieldName := range fieldNames {
+		fieldType := typ.Properties[fieldName]
+		fc := constraints[fieldName]
+		if fc == nil {
+			fc = &goFieldConstraints{}
+		}
+		if lit, ok := g.goDefaultLiteral(fieldType); ok {
+			base[fieldName] = lit
+		} else if required[fieldName] || constraints[fieldName] != nil || len(g.goEnumMembers(fieldType)) > 0 {
+			if lit, ok := g.goSampleLiteral(fieldType, fc, referable); ok {
+				base[fieldName] = lit
+			}
+		}
+		if _, ok := base[fieldName]; !ok && required[fieldName] && g.isNilable(fieldType) {
+			// the required attribute may not be sampled, such as the schemas not generated
+			continue
+		}
+
+		if members := g.goEnumMembers(fieldType); len(members) > 0 {
+			for _, m := range members {
+				valid = append(valid, goFixture{Comment: fmt.Sprintf("the member %s of %s", m, fieldName), Field: fieldName, Value: m})
+			}
+			if lit, ok := g.goNonMember(fieldType); ok {
+				invalid = append(invalid, goFixture{Comment: fmt.Sprintf("%s is not a member of the enum", fieldName), Field: fieldName, Value: lit})
+			}
+		}
+		if fc.Min != nil {
+			valid = append(valid, goFixture{Comment: fmt.Sprintf("the minimum of %s", fieldName), Field: fieldName, Value: strconv.Itoa(*fc.Min)})
+			invalid = append(invalid, goFixture{Comment: fmt.Sprintf("%s is less than the minimum %d", fieldName, *fc.Min), Field: fieldName, Value: strconv.Itoa(*fc.Min - 1)})
+		}
+		if fc.Max != nil {
+			valid = append(valid, goFixture{Comment: fmt.Sprintf("the maximum of %s", fieldName), Field: fieldName, Value: strconv.Itoa(*fc.Max)})
+			invalid = append(invalid, goFixture{Comment: fmt.Sprintf("%s is greater than the maximum %d", fieldName, *fc.Max), Field: fieldName, Value: strconv.Itoa(*fc.Max + 1)})
+		}
+		if fc.MinLen != nil {
+			if lit, ok := g.goSizedLiteral(fieldType, *fc.MinLen, referable); ok {
+				valid = append(valid, goFixture{Comment: fmt.Sprintf("the minimum length of %s", fieldName), Field: fieldName, Value: lit})
+			}
+			if *fc.MinLen > 0 {
+				if lit, ok := g.goSizedLiteral(fieldType, *fc.MinLen-1, referable); ok {
+					invalid = append(invalid, goFixture{Comment: fmt.Sprintf("%s is shorter than the minimum length %d", fieldName, *fc.MinLen), Field: fieldName, Value: lit})
+				}
+			}
+		}
+		if fc.MaxLen != nil {
+			if lit, ok := g.goSizedLiteral(fieldType, *fc.MaxLen, referable); ok {
+				valid = append(valid, goFixture{Comment: fmt.Sprintf("the maximum length of %s", fieldName), Field: fieldName, Value: lit})
+			}
+			if lit, ok := g.goSizedLiteral(fieldType, *fc.MaxLen+1, referable); ok {
+				invalid = append(invalid, goFixture{Comment: fmt.Sprintf("%s is longer than the maximum length %d", fieldName, *fc.MaxLen), Field: fieldName, Value: lit})
+			}
+		}
+		if required[fieldName] && g.isNilable(fieldType) {
+			invalid = append(invalid, goFixture{Comment: fmt.Sprintf("the required %s is not set", fieldName), Field: fieldName, Value: "nil"})
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// %sFixtures returns the sample instances of %s covering the defaults, the bounds and the enum members\n", name, typ.SchemaName)
+	fmt.Fprintf(w, "// of the attributes, as the seeds of the fuzz and property tests.\n")
+	g.writeFixtures(w, name+"Fixtures", typ.SchemaName, fieldNames, base, valid)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// %sInvalidFixtures returns the instances of %s violating each constraint, for the negative tests.\n", name, typ.SchemaName)
+	g.writeFixtures(w, name+"InvalidFixtures", typ.SchemaName, fieldNames, base, invalid)
+}
+
+// writeFixtures writes the function returning the fixtures, each of which is the base instance with the attribute
+// overridden. The duplicated instances are written once
+func (g *goGenerator) writeFixtures(w io.Writer, funcName string, schemaName string, fieldNames []string, base map[string]string, fixtures []goFixture) {
+	fmt.Fprintf(w, "func %s() []*%s {\n", funcName, schemaName)
+	if len(fixtures) == 0 {
+		fmt.Fprintf(w, "    return nil\n}\n")
+		return
+	}
+	fmt.Fprintf(w, "    return []*%s{\n", schemaName)
+	seen := map[string]bool{}
+	for _, f := range fixtures {
+		var fields []string
+		for _, fieldName := range fieldNames {
+			value, ok := base[fieldName]
+			if fieldName == f.Field {
+				value, ok = f.Value, true
+			}
+			if ok {
+				fields = append(fields, fmt.Sprintf("%s: %s", fieldName, value))
+			}
+		}
+		instance := "{" + strings.Join(fields, ", ") + "}"
+		if seen[instance] {
+			continue
+		}
+		seen[instance] = true
+		fmt.Fprintf(w, "        // %s\n", f.Comment)
+		fmt.Fprintf(w, "        %s,\n", instance)
+	}
+	fmt.Fprintf(w, "    }\n}\n")
+}
+
+// reachesSchema checks if the type references the schema of the name, directly or through the attributes of the
+// referenced schemas. The attributes of the generated schemas are looked up by the schema names
+func reachesSchema(typ *pb.KclType, schemaName string, schemas map[string]*pb.KclType, visited map[string]bool) bool {
+	if typ == nil {
+		return false
+	}
+	switch typ.Type {
+	case typSchema:
+		if typ.SchemaName == schemaName {
+			return true
+		}
+		if visited[typ.SchemaName] {
+			return false
+		}
+		visited[typ.SchemaName] = true
+		properties := typ.Properties
+		if schema, ok := schemas[typ.SchemaName]; ok {
+			properties = schema.Properties
+		}
+		for _, prop := range properties {
+			if reachesSchema(prop, schemaName, schemas, visited) {
+				return true
+			}
+		}
+	case typList:
+		return reachesSchema(typ.Item, schemaName, schemas, visited)
+	case typDict:
+		return reachesSchema(typ.Key, schemaName, schemas, visited) || reachesSchema(typ.Item, schemaName, schemas, visited)
+	case typUnion:
+		for _, t := range typ.UnionTypes {
+			if reachesSchema(t, schemaName, schemas, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goSampleLiteral returns the Go literal of a valid sample value of the attribute within its bounds. The schema
+// attributes reference the first fixture of the referable schemas
+func (g *goGenerator) goSampleLiteral(typ *pb.KclType, fc *goFieldConstraints, referable map[string]bool) (string, bool) {
+	if members := g.goEnumMembers(typ); len(members) > 0 {
+		return members[0], true
+	}
+	switch goBasicType(typ) {
+	case typInt, typFloat, typNumberMultiplier:
+		v := 0
+		if fc.Min != nil && v < *fc.Min {
+			v = *fc.Min
+		}
+		if fc.Max != nil && v > *fc.Max {
+			v = *fc.Max
+		}
+		return strconv.Itoa(v), true
+	case typBool:
+		return "false", true
+	case typStr, typList:
+		n := 0
+		if fc.MinLen != nil {
+			n = *fc.MinLen
+		}
+		return g.goSizedLiteral(typ, n, referable)
+	case typDict:
+		return g.GetTypeName(typ) + "{}", true
+	case typSchema:
+		if !referable[typ.SchemaName] {
+			return "", false
+		}
+		lit := fmt.Sprintf("%sFixtures()[0]", goExportedName(typ.SchemaName))
+		if g.opts.UseValue {
+			lit = "*" + lit
+		}
+		return lit, true
+	}
+	return "", false
+}
+
+// goSizedLiteral returns the Go literal of a string or list attribute of the length
+func (g *goGenerator) goSizedLiteral(typ *pb.KclType, n int, referable map[string]bool) (string, bool) {
+	switch typ.Type {
+	case typStr:
+		return strconv.Quote(strings.Repeat("a", n)), true
+	case typList:
+		if n == 0 {
+			return g.GetTypeName(typ) + "{}", true
+		}
+		item, ok := g.goSampleLiteral(typ.Item, &goFieldConstraints{}, referable)
+		if !ok {
+			return "", false
+		}
+		items := make([]string, n)
+		for i := range items {
+			items[i] = item
+		}
+		return fmt.Sprintf("%s{%s}", g.GetTypeName(typ), strings.Join(items, ", ")), true
+	}
+	return "", false
+}
+
+// goEnumMembers returns the Go literals of the members of the literal union type, or of the literal type
+func (g *goGenerator) goEnumMembers(typ *pb.KclType) []string {
+	types := []*pb.KclType{typ}
+	if typ.Type == typUnion {
+		types = typ.UnionTypes
+	}
+	var members []string
+	for _, t := range types {
+		isLit, _, litValue := IsLitType(t)
+		if !isLit {
+			return nil
+		}
+		value, ok := kclDefaultValue(litValue)
+		if !ok {
+			return nil
+		}
+		lit, ok := g.goLiteral(t, value)
+		if !ok {
+			return nil
+		}
+		members = append(members, lit)
+	}
+	return members
+}
+
+// goNonMember returns the Go literal of a value of the enum type which is not any member. The bool enums have no
+// such value
+func (g *goGenerator) goNonMember(typ *pb.KclType) (string, bool) {
+	members := map[string]bool{}
+	for _, m := range g.goEnumMembers(typ) {
+		members[m] = true
+	}
+	basicTyp := goBasicType(typ)
+	if typ.Type == typUnion && len(typ.UnionTypes) > 0 {
+		basicTyp = goBasicType(typ.UnionTypes[0])
+	}
+	switch basicTyp {
+	case typStr:
+		for s := "invalid"; ; s += "_" {
+			if lit := strconv.Quote(s); !members[lit] {
+				return lit, true
+			}
+		}
+	case typInt, typFloat:
+		var values []float64
+		for m := range members {
+			if v, err := strconv.ParseFloat(m, 64); err == nil {
+				values = append(values, v)
+			}
+		}
+		sort.Float64s(values)
+		if len(values) == 0 {
+			return "", false
+		}
+		return strconv.FormatFloat(values[len(values)-1]+1, 'f', -1, 64), true
+	}
+	return "", false
+}
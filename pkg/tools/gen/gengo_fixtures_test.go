@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+	pb "kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+func TestGenGoTestFixtures(t *testing.T) {
+	source := `schema Person:
+    name: str
+    age: int = 18
+    role: "admin" | "user" = "user"
+    tags?: [str]
+    ratio?: float
+
+    check:
+        0 <= age <= 120
+        len(name) >= 1
+        len(tags) <= 2
+        ratio >= 1 if tags
+
+schema Team:
+    lead: Person
+    members?: [Person]
+
+schema Node:
+    value: int
+    next: Node
+
+schema Tree:
+    root: Branch
+
+schema Branch:
+    tree: Tree
+`
+	strType := &pb.KclType{Type: typStr}
+	person := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Person",
+		Properties: map[string]*pb.KclType{
+			"name":  {Type: typStr, Line: 1},
+			"age":   {Type: typInt, Default: "18", Line: 2},
+			"role":  {Type: typUnion, UnionTypes: []*pb.KclType{{Type: `str(admin)`}, {Type: `str(user)`}}, Default: `"user"`, Line: 3},
+			"tags":  {Type: typList, Item: strType, Line: 4},
+			"ratio": {Type: typFloat, Line: 5},
+		},
+		Required: []string{"name", "age", "role"},
+	}
+	team := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Team",
+		Properties: map[string]*pb.KclType{
+			"lead":    {Type: typSchema, SchemaName: "Person", Line: 1},
+			"members": {Type: typList, Item: &pb.KclType{Type: typSchema, SchemaName: "Person"}, Line: 2},
+		},
+		Required: []string{"lead"},
+	}
+
+	node := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Node",
+		Properties: map[string]*pb.KclType{
+			"value": {Type: typInt, Line: 1},
+			"next":  {Type: typSchema, SchemaName: "Node", Line: 2},
+		},
+		Required: []string{"value", "next"},
+	}
+	tree := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Tree",
+		Properties: map[string]*pb.KclType{"root": {Type: typSchema, SchemaName: "Branch", Line: 1}},
+		Required:   []string{"root"},
+	}
+	branch := &pb.KclType{
+		Type:       typSchema,
+		SchemaName: "Branch",
+		Properties: map[string]*pb.KclType{"tree": {Type: typSchema, SchemaName: "Tree", Line: 1}},
+		Required:   []string{"tree"},
+	}
+
+	var buf bytes.Buffer
+	g := newGoGenerator(&GenGoOptions{AnyType: goAnyType, EmitTestFixtures: true})
+	g.source = source
+	g.GenFromTypes(&buf, person, team, node, tree, branch)
+	code := buf.String()
+	assert2.Contains(t, code, `func PersonFixtures() []*Person {
+    return []*Person{
+        // the defaults
+        {name: "a", age: 18, role: "user", tags: []string{}},
+        // the minimum of age
+        {name: "a", age: 0, role: "user", tags: []string{}},
+        // the maximum of age
+        {name: "a", age: 120, role: "user", tags: []string{}},
+        // the member "admin" of role
+        {name: "a", age: 18, role: "admin", tags: []string{}},
+        // the maximum length of tags
+        {name: "a", age: 18, role: "user", tags: []string{"", ""}},
+    }
+}`)
+	assert2.Contains(t, code, `func PersonInvalidFixtures() []*Person {
+    return []*Person{
+        // name is shorter than the minimum length 1
+        {name: "", age: 18, role: "user", tags: []string{}},
+        // age is less than the minimum 0
+        {name: "a", age: -1, role: "user", tags: []string{}},
+        // age is greater than the maximum 120
+        {name: "a", age: 121, role: "user", tags: []string{}},
+        // role is not a member of the enum
+        {name: "a", age: 18, role: "invalid", tags: []string{}},
+        // tags is longer than the maximum length 2
+        {name: "a", age: 18, role: "user", tags: []string{"", "", ""}},
+    }
+}`)
+	assert2.Contains(t, code, `func TeamFixtures() []*Team {
+    return []*Team{
+        // the defaults
+        {lead: PersonFixtures()[0]},
+    }
+}`)
+	assert2.Contains(t, code, `        // the required lead is not set
+        {lead: nil},`)
+	// the recursive schemas do not reference their own fixtures
+	assert2.Contains(t, code, `func NodeFixtures() []*Node {
+    return []*Node{
+        // the defaults
+        {value: 0},
+    }
+}`)
+	assert2.Contains(t, code, `func TreeFixtures() []*Tree {
+    return []*Tree{
+        // the defaults
+        {},
+    }
+}`)
+	assert2.NotContains(t, code, "BranchFixtures()[0]")
+
+	buf.Reset()
+	newGoGenerator(&GenGoOptions{AnyType: goAnyType}).GenFromTypes(&buf, person)
+	assert2.NotContains(t, buf.String(), "PersonFixtures")
+
+	// compile and run the generated fixtures
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+	dir := t.TempDir()
+	main := "package main\n\nimport \"fmt\"\n" + code + `
+func main() {
+    fmt.Println(len(PersonFixtures()), len(PersonInvalidFixtures()), len(TeamFixtures()), TeamFixtures()[0].lead.age, len(NodeFixtures()), len(TreeFixtures()))
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to compile the generated fixtures: %s\n%s", err, out)
+	}
+	assert2.Equal(t, "5 5 1 18 1 1\n", string(out))
+}
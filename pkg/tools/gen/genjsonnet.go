@@ -0,0 +1,291 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"kcl-lang.io/kcl-go/pkg/kcl"
+)
+
+const jsonnetIndent = "  "
+
+// jsonnetIdentRegexp matches the Jsonnet identifiers which can be used as the object field names without quotes
+var jsonnetIdentRegexp = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+
+// jsonnetKeywords are the reserved words of Jsonnet which can not be used as the identifiers
+var jsonnetKeywords = map[string]bool{
+	"assert": true, "else": true, "error": true, "false": true, "for": true, "function": true, "if": true,
+	"import": true, "importstr": true, "importbin": true, "in": true, "local": true, "null": true,
+	"tailstrict": true, "then": true, "self": true, "super": true, "true": true,
+}
+
+type GenJsonnetOptions struct {
+	// DisableLocalFunctions defines whether to inline the objects of the repeated shapes instead of extracting them
+	// to the local functions
+	DisableLocalFunctions bool
+}
+
+// GenJsonnet evaluates the KCL file and translates the result instances to a Jsonnet object. The objects with the
+// same fields repeated in the result are constructed with the generated local functions.
+func GenJsonnet(w io.Writer, filename string, src interface{}, opts *GenJsonnetOptions) error {
+	if opts == nil {
+		opts = new(GenJsonnetOptions)
+	}
+	code, err := readSource(filename, src)
+	if err != nil {
+		return err
+	}
+	r, err := kcl.RunFiles([]string{filename}, kcl.WithCode(string(code)), kcl.WithWorkDir(filepath.Dir(filename)))
+	if err != nil {
+		return err
+	}
+	return genJsonnetFromJson(w, []byte(r.GetRawJsonResult()), opts)
+}
+
+// genJsonnetFromJson translates the JSON data to a Jsonnet object, keeping the order of the object fields
+func genJsonnetFromJson(w io.Writer, data []byte, opts *GenJsonnetOptions) error {
+	var value interface{}
+	if err := yaml.UnmarshalWithOptions(data, &value, yaml.UseOrderedMap(), yaml.UseJSONUnmarshaler()); err != nil {
+		return err
+	}
+	g := &jsonnetGenerator{shapes: map[string]*jsonnetShape{}}
+	if !opts.DisableLocalFunctions {
+		g.collectShapes(value)
+	}
+	var buf bytes.Buffer
+	n := 0
+	for _, shape := range g.ordered {
+		if shape.Count < 2 {
+			continue
+		}
+		n++
+		shape.Name = fmt.Sprintf("shape%d", n)
+		fmt.Fprintf(&buf, "local %s(%s) = {\n", shape.Name, strings.Join(shape.Keys, ", "))
+		for _, key := range shape.Keys {
+			fmt.Fprintf(&buf, "%s%s: %s,\n", jsonnetIndent, key, key)
+		}
+		buf.WriteString("};\n\n")
+	}
+	buf.WriteString(g.render(value, ""))
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// jsonnetShape is the field names of the objects, which are constructed with a local function if repeated
+type jsonnetShape struct {
+	Name  string
+	Keys  []string
+	Count int
+}
+
+type jsonnetGenerator struct {
+	shapes  map[string]*jsonnetShape // shapes by the field names joined
+	ordered []*jsonnetShape          // shapes in the order of the first appearance
+}
+
+// collectShapes counts the shapes of the objects in the value. Only the objects whose field names are all valid
+// Jsonnet identifiers are counted, so the field names can be used as the function parameters
+func (g *jsonnetGenerator) collectShapes(value interface{}) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		if keys, ok := jsonnetShapeKeys(v); ok {
+			signature := strings.Join(keys, ",")
+			shape, ok := g.shapes[signature]
+			if !ok {
+				shape = &jsonnetShape{Keys: keys}
+				g.shapes[signature] = shape
+				g.ordered = append(g.ordered, shape)
+			}
+			shape.Count++
+		}
+		for _, item := range v {
+			g.collectShapes(item.Value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			g.collectShapes(item)
+		}
+	}
+}
+
+// jsonnetShapeKeys returns the field names of the object if they can be used as the function parameters
+func jsonnetShapeKeys(obj yaml.MapSlice) ([]string, bool) {
+	if len(obj) == 0 {
+		return nil, false
+	}
+	keys := make([]string, len(obj))
+	for i, item := range obj {
+		key := fmt.Sprint(item.Key)
+		if !isJsonnetIdent(key) {
+			return nil, false
+		}
+		keys[i] = key
+	}
+	return keys, true
+}
+
+// render renders the value as a Jsonnet expression at the indentation
+func (g *jsonnetGenerator) render(value interface{}, indent string) string {
+	inner := indent + jsonnetIndent
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		if keys, ok := jsonnetShapeKeys(v); ok {
+			if shape := g.shapes[strings.Join(keys, ",")]; shape != nil && shape.Name != "" {
+				args := make([]string, len(v))
+				multiline := false
+				for i, item := range v {
+					args[i] = g.render(item.Value, inner)
+					multiline = multiline || strings.Contains(args[i], "\n")
+				}
+				if !multiline {
+					return fmt.Sprintf("%s(%s)", shape.Name, strings.Join(args, ", "))
+				}
+				return fmt.Sprintf("%s(\n%s%s\n%s)", shape.Name, inner, strings.Join(args, ",\n"+inner), indent)
+			}
+		}
+		if len(v) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, item := range v {
+			fmt.Fprintf(&b, "%s%s: %s,\n", inner, jsonnetFieldName(fmt.Sprint(item.Key)), g.render(item.Value, inner))
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range v {
+			fmt.Fprintf(&b, "%s%s,\n", inner, g.render(item, inner))
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	}
+	return jsonnetScalar(value)
+}
+
+// jsonnetScalar renders the scalar value as a Jsonnet literal
+func jsonnetScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return jsonnetString(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonnetString renders the string as a Jsonnet string literal, which is compatible with the JSON string
+func jsonnetString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// jsonnetFieldName renders the object field name, which is quoted if it is not a valid identifier
+func jsonnetFieldName(name string) string {
+	if isJsonnetIdent(name) {
+		return name
+	}
+	return jsonnetString(name)
+}
+
+func isJsonnetIdent(name string) bool {
+	return jsonnetIdentRegexp.MatchString(name) && !jsonnetKeywords[name]
+}
+
+// ExportJsonnetLibrary exports the schemas of the kcl package as a Jsonnet library. Each schema is exported as a
+// hidden object constructor with the attribute defaults, e.g. `Server(args={}):: { port: 80 } + args`, which asserts
+// the required attributes without defaults are set. The schemas of the sub packages are nested in the hidden
+// objects named by the sub packages.
+func ExportJsonnetLibrary(spec *SwaggerV2Spec) []byte {
+	pkg := spec.toKclPackage()
+	pkg.sortSchemasAndPkgs()
+	var buf bytes.Buffer
+	buf.WriteString("// Auto generated by kcl-doc tool, please do not edit.\n")
+	writeJsonnetPackage(&buf, pkg, "")
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// writeJsonnetPackage writes the object of the schema constructors and sub packages of the kcl package
+func writeJsonnetPackage(buf *bytes.Buffer, pkg *KclPackage, indent string) {
+	inner := indent + jsonnetIndent
+	buf.WriteString("{\n")
+	for _, schema := range pkg.SchemaList {
+		writeJsonnetSchema(buf, schema, inner)
+	}
+	for _, sub := range pkg.SubPackageList {
+		fmt.Fprintf(buf, "%s%s:: ", inner, jsonnetFieldName(sub.Name))
+		writeJsonnetPackage(buf, sub, inner)
+		buf.WriteString(",\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+// writeJsonnetSchema writes the object constructor of the schema
+func writeJsonnetSchema(buf *bytes.Buffer, schema *KclOpenAPIType, indent string) {
+	inner := indent + jsonnetIndent
+	name := schema.KclExtensions.XKclModelType.Type
+	if doc := docText(schema.Description); doc != "" {
+		for _, line := range strings.Split(doc, "\n") {
+			fmt.Fprintf(buf, "%s%s\n", indent, strings.TrimRight("// "+line, " "))
+		}
+	}
+	fmt.Fprintf(buf, "%s%s(args={}):: {\n", indent, jsonnetFieldName(name))
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	var defaults []string
+	for _, attr := range getSortedKeys(schema.Properties) {
+		value, ok := jsonnetDefault(schema.Properties[attr].Default, inner)
+		if ok {
+			defaults = append(defaults, fmt.Sprintf("%s%s: %s,\n", inner, jsonnetFieldName(attr), value))
+		} else if required[attr] {
+			fmt.Fprintf(buf, "%sassert std.objectHas(self, %s) : %s,\n", inner, jsonnetString(attr), jsonnetString(fmt.Sprintf("%s.%s is required", name, attr)))
+		}
+	}
+	buf.WriteString(strings.Join(defaults, ""))
+	fmt.Fprintf(buf, "%s} + args,\n", indent)
+}
+
+// jsonnetDefault translates the KCL literal of the attribute default to the Jsonnet literal. The defaults which are
+// not the literals, e.g. the expressions, are not translated
+func jsonnetDefault(literal string, indent string) (string, bool) {
+	switch literal {
+	case "":
+		return "", false
+	case "True":
+		return "true", true
+	case "False":
+		return "false", true
+	case "None":
+		return "null", true
+	}
+	if isUnitLiteral(literal) {
+		return jsonnetString(literal), true
+	}
+	var value interface{}
+	if err := yaml.UnmarshalWithOptions([]byte(literal), &value, yaml.UseOrderedMap(), yaml.UseJSONUnmarshaler()); err != nil || !json.Valid([]byte(literal)) {
+		return "", false
+	}
+	g := &jsonnetGenerator{shapes: map[string]*jsonnetShape{}}
+	return g.render(value, indent), true
+}
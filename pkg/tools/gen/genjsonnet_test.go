@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func TestGenJsonnetFromJson(t *testing.T) {
+	input := readFileString(t, filepath.Join("testdata", "jsonnet", "input.json"))
+	for expectFile, opts := range map[string]*GenJsonnetOptions{
+		"expect.jsonnet":       {},
+		"expect_plain.jsonnet": {DisableLocalFunctions: true},
+	} {
+		expect := readFileString(t, filepath.Join("testdata", "jsonnet", expectFile))
+		var buf bytes.Buffer
+		if err := genJsonnetFromJson(&buf, []byte(input), opts); err != nil {
+			t.Fatal(err)
+		}
+		assert2.Equal(t, expect, buf.String())
+	}
+}
+
+// evalJsonnet evaluates the Jsonnet code with the jsonnet command in the directory and returns the JSON result,
+// the test is skipped if the jsonnet command is not available
+func evalJsonnet(t *testing.T, dir string, code string) interface{} {
+	jsonnet, err := exec.LookPath("jsonnet")
+	if err != nil {
+		t.Skip("jsonnet is not available")
+	}
+	cmd := exec.Command(jsonnet, "-e", code)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to evaluate jsonnet: %s\n%s", err, out)
+	}
+	var result interface{}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestGenJsonnetRoundTrip(t *testing.T) {
+	input := readFileString(t, filepath.Join("testdata", "jsonnet", "input.json"))
+	var expected interface{}
+	if err := json.Unmarshal([]byte(input), &expected); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []*GenJsonnetOptions{{}, {DisableLocalFunctions: true}} {
+		var buf bytes.Buffer
+		if err := genJsonnetFromJson(&buf, []byte(input), opts); err != nil {
+			t.Fatal(err)
+		}
+		assert2.Equal(t, expected, evalJsonnet(t, t.TempDir(), buf.String()))
+	}
+}
+
+func TestExportJsonnetLibrary(t *testing.T) {
+	server := newTestSchema("Server", "Server is a server.", []string{"host", "port"}, map[string]*KclOpenAPIType{
+		"host":   {Type: String},
+		"port":   {Type: Integer, Format: Int64, Default: "80"},
+		"tls":    {Type: Bool, Default: "False"},
+		"labels": {Type: Object, AdditionalProperties: &KclOpenAPIType{Type: String}, Default: `{"app": "web"}`},
+		"name":   {Type: String, Default: `"web" + "-" + "server"`},
+	})
+	volume := newTestSchema("Volume", "", []string{"path"}, map[string]*KclOpenAPIType{
+		"path": {Type: String},
+	})
+	volume.KclExtensions.XKclModelType.Import.Package = "storage"
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "storage.Volume": volume}}
+	library := string(ExportJsonnetLibrary(spec))
+	assert2.Equal(t, `// Auto generated by kcl-doc tool, please do not edit.
+{
+  // Server is a server.
+  Server(args={}):: {
+    assert std.objectHas(self, "host") : "Server.host is required",
+    labels: {
+      app: "web",
+    },
+    port: 80,
+    tls: false,
+  } + args,
+  storage:: {
+    Volume(args={}):: {
+      assert std.objectHas(self, "path") : "Volume.path is required",
+    } + args,
+  },
+}
+`, library)
+
+	g := newTestGenContext(t, Jsonnet)
+	files := renderTestDoc(t, g, spec)
+	assert2.Equal(t, library, files["main.libsonnet"])
+
+	got := evalJsonnet(t, g.Target, `(import "main.libsonnet").Server({host: "example.com"})`)
+	assert2.Equal(t, map[string]interface{}{
+		"host":   "example.com",
+		"labels": map[string]interface{}{"app": "web"},
+		"port":   float64(80),
+		"tls":    false,
+	}, got)
+}
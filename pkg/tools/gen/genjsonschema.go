@@ -0,0 +1,207 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	jsonSchemaDraft       = "https://json-schema.org/draft/2020-12/schema"
+	jsonSchemaDefsRef     = "#/$defs/"
+	jsonSchemaCatalogFile = "catalog.json"
+)
+
+// ExportJsonSchema exports a single JSON Schema document of the kcl package. All the schemas are put into `$defs`
+// and referenced by `#/$defs/<schema id>`
+func ExportJsonSchema(spec *SwaggerV2Spec) map[string]interface{} {
+	defs := make(map[string]interface{}, len(spec.Definitions))
+	for id, ty := range spec.Definitions {
+		defs[id] = exportJsonSchemaType(ty, func(id string) string {
+			return jsonSchemaDefsRef + id
+		})
+	}
+	doc := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"$defs":   defs,
+	}
+	if spec.Info.Title != "" {
+		doc["title"] = spec.Info.Title
+	}
+	if spec.Info.Description != "" {
+		doc["description"] = spec.Info.Description
+	}
+	return doc
+}
+
+// ExportJsonSchemaFiles exports one JSON Schema file per schema of the kcl package, which can be used for the per-file
+// validation in editors. The schemas reference each other with the relative path of the sibling files, and a
+// `catalog.json` file mapping the schema ids to the file paths is exported as well.
+// The returned map is from the file path to the file content.
+func ExportJsonSchemaFiles(spec *SwaggerV2Spec) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(spec.Definitions)+1)
+	catalog := make(map[string]string, len(spec.Definitions))
+	for id, ty := range spec.Definitions {
+		doc := exportJsonSchemaType(ty, jsonSchemaFileName)
+		doc["$schema"] = jsonSchemaDraft
+		doc["$id"] = jsonSchemaFileName(id)
+		content, err := json.MarshalIndent(doc, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to export json schema of %s: %s", id, err)
+		}
+		files[jsonSchemaFileName(id)] = content
+		catalog[id] = jsonSchemaFileName(id)
+	}
+	content, err := json.MarshalIndent(catalog, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	files[jsonSchemaCatalogFile] = content
+	return files, nil
+}
+
+// jsonSchemaFileName returns the JSON Schema file name of the schema id
+func jsonSchemaFileName(id string) string {
+	return id + ".json"
+}
+
+// exportJsonSchemaType converts the kcl type to JSON Schema, the schema references are resolved by the ref function
+func exportJsonSchemaType(ty *KclOpenAPIType, ref func(id string) string) map[string]interface{} {
+	s := map[string]interface{}{}
+	if ty.Description != "" {
+		s["description"] = docText(ty.Description)
+	}
+	if ty.Ref != "" {
+		s["$ref"] = ref(Ref2SchemaId(ty.Ref))
+		return s
+	}
+	if ty.ReadOnly && len(ty.Enum) > 0 {
+		if v, ok := kclLiteralToJson(ty.Enum[0]); ok {
+			s["const"] = v
+			return s
+		}
+	}
+	switch ty.Type {
+	case String:
+		s["type"] = "string"
+	case Integer:
+		if ty.Format == NumberMultiplier {
+			s["type"] = "string"
+		} else {
+			s["type"] = "integer"
+		}
+	case Number:
+		s["type"] = "number"
+	case Bool:
+		s["type"] = "boolean"
+	case Array:
+		s["type"] = "array"
+		if ty.Items != nil && !ty.Items.isAnyType() {
+			s["items"] = exportJsonSchemaType(ty.Items, ref)
+		}
+	case Object:
+		switch {
+		case ty.KclExtensions != nil && len(ty.KclExtensions.XKclUnionTypes) > 0:
+			anyOf := make([]interface{}, len(ty.KclExtensions.XKclUnionTypes))
+			for i, t := range ty.KclExtensions.XKclUnionTypes {
+				anyOf[i] = exportJsonSchemaType(t, ref)
+			}
+			s["anyOf"] = anyOf
+		case ty.AdditionalProperties != nil:
+			s["type"] = "object"
+			if !ty.AdditionalProperties.isAnyType() {
+				s["additionalProperties"] = exportJsonSchemaType(ty.AdditionalProperties, ref)
+			}
+		case ty.Properties != nil:
+			s["type"] = "object"
+			if ty.KclExtensions != nil && ty.KclExtensions.XKclModelType != nil {
+				s["title"] = ty.KclExtensions.XKclModelType.Type
+			}
+			props := make(map[string]interface{}, len(ty.Properties))
+			for name, prop := range ty.Properties {
+				props[name] = exportJsonSchemaType(prop, ref)
+			}
+			s["properties"] = props
+			if len(ty.Required) > 0 {
+				s["required"] = ty.Required
+			}
+			if ty.KclExtensions != nil {
+				exportJsonSchemaAttributeGroups(s, ty.KclExtensions.XKclAttributeGroups)
+			}
+		}
+	}
+	if ty.Default != "" && !ty.ReadOnly {
+		if v, ok := kclLiteralToJson(ty.Default); ok {
+			s["default"] = v
+		}
+	}
+	if ty.KclExtensions != nil && ty.KclExtensions.XKclUnit != "" {
+		s[ExtensionKclUnit] = ty.KclExtensions.XKclUnit
+	}
+	return s
+}
+
+// exportJsonSchemaAttributeGroups exports the attribute groups as the presence constraints: `oneOf` for exactly one,
+// `anyOf` for at least one, and `not` of any pair for at most one of the attributes. More than one group are
+// combined with `allOf`
+func exportJsonSchemaAttributeGroups(s map[string]interface{}, groups []*XKclAttributeGroup) {
+	requires := func(names ...string) map[string]interface{} {
+		return map[string]interface{}{"required": names}
+	}
+	var constraints []map[string]interface{}
+	for _, group := range groups {
+		var each []interface{}
+		for _, name := range group.Attributes {
+			each = append(each, requires(name))
+		}
+		switch group.Kind {
+		case ExactlyOne:
+			constraints = append(constraints, map[string]interface{}{"oneOf": each})
+		case AtLeastOne:
+			constraints = append(constraints, map[string]interface{}{"anyOf": each})
+		case AtMostOne:
+			var pairs []interface{}
+			for i, a := range group.Attributes {
+				for _, b := range group.Attributes[i+1:] {
+					pairs = append(pairs, requires(a, b))
+				}
+			}
+			constraints = append(constraints, map[string]interface{}{"not": map[string]interface{}{"anyOf": pairs}})
+		}
+	}
+	if len(constraints) == 1 {
+		for k, v := range constraints[0] {
+			s[k] = v
+		}
+	} else if len(constraints) > 1 {
+		allOf := make([]interface{}, len(constraints))
+		for i, c := range constraints {
+			allOf[i] = c
+		}
+		s["allOf"] = allOf
+	}
+}
+
+// kclLiteralToJson converts the kcl literal value such as `True` and `"foo"` to the JSON value
+func kclLiteralToJson(lit string) (interface{}, bool) {
+	switch lit {
+	case "True":
+		return true, true
+	case "False":
+		return false, true
+	case "None":
+		return nil, true
+	}
+	if s, err := strconv.Unquote(lit); err == nil {
+		return s, true
+	}
+	if isUnitLiteral(lit) {
+		// the quantities such as `1Gi` are kept as the strings with the units
+		return lit, true
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(lit), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,147 @@
+package gen
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert2 "github.com/stretchr/testify/assert"
+)
+
+func newJsonSchemaTestSpec() *SwaggerV2Spec {
+	return &SwaggerV2Spec{
+		Definitions: map[string]*KclOpenAPIType{
+			"models.Person": {
+				Type:        Object,
+				Description: "Person is a person record.",
+				Properties: map[string]*KclOpenAPIType{
+					"name": {Type: String},
+					"age":  {Type: Integer, Format: Int64, Default: "18"},
+					"address": {
+						Ref: SchemaId2Ref("models.address.Address"),
+					},
+					"pets": {
+						Type:  Array,
+						Items: &KclOpenAPIType{Ref: SchemaId2Ref("Pet")},
+					},
+				},
+				Required: []string{"name"},
+				KclExtensions: &KclExtensions{
+					XKclModelType: &XKclModelType{Type: "Person", Import: &KclModelImportInfo{Package: "models"}},
+				},
+			},
+			"models.address.Address": {
+				Type: Object,
+				Properties: map[string]*KclOpenAPIType{
+					"city": {Type: String},
+				},
+				KclExtensions: &KclExtensions{
+					XKclModelType: &XKclModelType{Type: "Address", Import: &KclModelImportInfo{Package: "models.address"}},
+				},
+			},
+			"Pet": {
+				Type: Object,
+				Properties: map[string]*KclOpenAPIType{
+					"kind": {
+						Type: Object,
+						KclExtensions: &KclExtensions{
+							XKclUnionTypes: []*KclOpenAPIType{
+								{Type: String, ReadOnly: true, Default: `"cat"`, Enum: []string{`"cat"`}},
+								{Type: String, ReadOnly: true, Default: `"dog"`, Enum: []string{`"dog"`}},
+							},
+						},
+					},
+					"vaccinated": {Type: Bool, Default: "True"},
+				},
+				KclExtensions: &KclExtensions{
+					XKclModelType: &XKclModelType{Type: "Pet", Import: &KclModelImportInfo{}},
+				},
+			},
+		},
+	}
+}
+
+func TestExportJsonSchema(t *testing.T) {
+	doc := ExportJsonSchema(newJsonSchemaTestSpec())
+	got, err := json.Marshal(doc["$defs"].(map[string]interface{})["Pet"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, `{"properties":{"kind":{"anyOf":[{"const":"cat"},{"const":"dog"}]},"vaccinated":{"default":true,"type":"boolean"}},"title":"Pet","type":"object"}`, string(got))
+	got, err = json.Marshal(doc["$defs"].(map[string]interface{})["models.Person"].(map[string]interface{})["properties"].(map[string]interface{})["address"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, `{"$ref":"#/$defs/models.address.Address"}`, string(got))
+}
+
+func TestExportJsonSchemaFiles(t *testing.T) {
+	files, err := ExportJsonSchemaFiles(newJsonSchemaTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := map[string]string{}
+	if err := json.Unmarshal(files[jsonSchemaCatalogFile], &catalog); err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, map[string]string{
+		"Pet":                    "Pet.json",
+		"models.Person":          "models.Person.json",
+		"models.address.Address": "models.address.Address.json",
+	}, catalog)
+
+	var person struct {
+		Id         string `json:"$id"`
+		Properties map[string]struct {
+			Ref   string `json:"$ref"`
+			Items struct {
+				Ref string `json:"$ref"`
+			} `json:"items"`
+			Default interface{} `json:"default"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(files["models.Person.json"], &person); err != nil {
+		t.Fatal(err)
+	}
+	assert2.Equal(t, "models.Person.json", person.Id)
+	assert2.Equal(t, []string{"name"}, person.Required)
+	assert2.Equal(t, float64(18), person.Properties["age"].Default)
+	// the refs are resolved to the sibling files
+	for _, ref := range []string{person.Properties["address"].Ref, person.Properties["pets"].Items.Ref} {
+		_, ok := files[ref]
+		assert2.True(t, ok, "unresolved ref %s", ref)
+	}
+}
+
+func TestExportJsonSchemaAttributeGroups(t *testing.T) {
+	newSchema := func(groups ...*XKclAttributeGroup) *KclOpenAPIType {
+		s := newTestSchema("Server", "", nil, map[string]*KclOpenAPIType{
+			"a": {Type: String}, "b": {Type: String}, "c": {Type: String},
+		})
+		s.KclExtensions.XKclAttributeGroups = groups
+		return s
+	}
+	ref := func(id string) string { return id }
+	required := func(names ...string) map[string]interface{} {
+		return map[string]interface{}{"required": names}
+	}
+
+	got := exportJsonSchemaType(newSchema(&XKclAttributeGroup{Kind: ExactlyOne, Attributes: []string{"a", "b"}}), ref)
+	assert2.Equal(t, []interface{}{required("a"), required("b")}, got["oneOf"])
+
+	got = exportJsonSchemaType(newSchema(&XKclAttributeGroup{Kind: AtLeastOne, Attributes: []string{"a", "b"}}), ref)
+	assert2.Equal(t, []interface{}{required("a"), required("b")}, got["anyOf"])
+
+	got = exportJsonSchemaType(newSchema(&XKclAttributeGroup{Kind: AtMostOne, Attributes: []string{"a", "b", "c"}}), ref)
+	assert2.Equal(t, map[string]interface{}{"anyOf": []interface{}{required("a", "b"), required("a", "c"), required("b", "c")}}, got["not"])
+
+	got = exportJsonSchemaType(newSchema(
+		&XKclAttributeGroup{Kind: ExactlyOne, Attributes: []string{"a", "b"}},
+		&XKclAttributeGroup{Kind: AtLeastOne, Attributes: []string{"b", "c"}},
+	), ref)
+	assert2.Equal(t, []interface{}{
+		map[string]interface{}{"oneOf": []interface{}{required("a"), required("b")}},
+		map[string]interface{}{"anyOf": []interface{}{required("b"), required("c")}},
+	}, got["allOf"])
+	assert2.Nil(t, got["oneOf"])
+}
@@ -5,17 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/goccy/go-yaml"
-	"kcl-lang.io/kcl-go/pkg/logger"
 )
 
 type GenKclOptions struct {
 	Mode         Mode
 	ParseFromTag bool
+	// Logger is the logger of the conversion warnings. Defaults to printing the warnings to the stderr
+	Logger Logger
 }
 
 // Mode is the mode of kcl schema code generation.
@@ -28,13 +30,14 @@ const (
 	ModeTerraformSchema
 	ModeJson
 	ModeYaml
+	ModeCsv
 )
 
 type kclGenerator struct {
 	opts *GenKclOptions
 }
 
-// GenKcl translate other formats to kcl schema code. Now support go struct and json schema.
+// GenKcl translate other formats to kcl schema code. Now support go struct, json schema, terraform schema, json, yaml and csv.
 func GenKcl(w io.Writer, filename string, src interface{}, opts *GenKclOptions) error {
 	return newKclGenerator(opts).GenSchema(w, filename, src)
 }
@@ -57,6 +60,8 @@ func (k *kclGenerator) GenSchema(w io.Writer, filename string, src interface{})
 		codeStr := string(code)
 		var i interface{}
 		switch {
+		case strings.EqualFold(filepath.Ext(filename), ".csv"):
+			k.opts.Mode = ModeCsv
 		case json.Unmarshal(code, &i) == nil:
 			switch {
 			case strings.Contains(codeStr, "$schema"):
@@ -86,6 +91,8 @@ func (k *kclGenerator) GenSchema(w io.Writer, filename string, src interface{})
 		return k.genKclFromJsonData(w, filename, src)
 	case ModeYaml:
 		return k.genKclFromYaml(w, filename, src)
+	case ModeCsv:
+		return k.genKclFromCsv(w, filename, src)
 	default:
 		return errors.New("unknown mode")
 	}
@@ -105,7 +112,7 @@ func (k *kclGenerator) genSchemaFromGoStruct(w io.Writer, filename string, src i
 		for _, field := range goStruct.Fields {
 			kclFieldName, kclFieldType, err := k.GetTypeName(field)
 			if err != nil {
-				logger.GetLogger().Warningf("get struct tag key kcl info err: %s, will generate kcl schema from the struct field metadata data, field info %#v", err.Error(), field)
+				k.logger().Warn("get struct tag key kcl info err, will generate kcl schema from the struct field metadata data", "err", err.Error(), "field", fmt.Sprintf("%#v", field))
 				kclFieldName, kclFieldType = k.GetKclTypeFromStructField(field)
 			}
 			fmt.Fprintf(w, "    %s: %s\n", kclFieldName, kclFieldType)
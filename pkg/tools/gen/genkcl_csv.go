@@ -0,0 +1,225 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// csvTypeHints are the type names accepted in the optional type-hint row
+// right after the csv header.
+var csvTypeHints = map[string]struct{}{
+	typStr:   {},
+	typInt:   {},
+	typFloat: {},
+	typBool:  {},
+	typAny:   {},
+}
+
+func (k *kclGenerator) genKclFromCsv(w io.Writer, filename string, src interface{}) error {
+	code, err := readSource(filename, src)
+	if err != nil {
+		return err
+	}
+	code = bytes.ReplaceAll(code, []byte("\r\n"), []byte("\n"))
+	reader := csv.NewReader(bytes.NewReader(code))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("empty csv input, the first row must be the attribute names")
+	}
+	header, err := csvAttributeNames(records[0])
+	if err != nil {
+		return err
+	}
+	rows := records[1:]
+	// firstRow is the 1-based row number of the first data row in the file
+	firstRow := 2
+
+	// the optional second row declares the type of each column
+	var hints []string
+	if len(rows) > 0 && isCsvTypeHintRow(rows[0]) {
+		hints = rows[0]
+		rows = rows[1:]
+		firstRow++
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if name == "" || name == "." {
+		name = "row"
+	}
+	result := schema{
+		Name:        strcase.ToCamel(name),
+		Description: strcase.ToCamel(name),
+	}
+	var instances []interface{}
+	types := make([]string, len(header))
+	for i, attr := range header {
+		hint := ""
+		if i < len(hints) {
+			hint = strings.TrimSpace(hints[i])
+		}
+		types[i] = csvColumnType(rows, i, hint)
+		result.Properties = append(result.Properties, property{
+			Name:     attr,
+			Type:     typePrimitive(types[i]),
+			Required: !csvColumnHasMissing(rows, i),
+		})
+	}
+	for n, row := range rows {
+		var record []data
+		for i, attr := range header {
+			if i >= len(row) || row[i] == "" {
+				// missing values are omitted and the attribute is optional
+				continue
+			}
+			value, err := csvValue(row[i], types[i])
+			if err != nil {
+				return fmt.Errorf("row %d, column %s: %v", firstRow+n, records[0][i], err)
+			}
+			record = append(record, data{
+				Key:   attr,
+				Value: value,
+			})
+		}
+		instances = append(instances, config{
+			Name: result.Name,
+			Data: record,
+		})
+	}
+
+	// generate kcl code
+	return k.genKcl(w, kclFile{
+		Schemas: []schema{result},
+		Data: []data{{
+			Key:   strcase.ToSnake(name),
+			Value: instances,
+		}},
+	})
+}
+
+// csvInvalidNameCharRegexp matches the characters which are not allowed in the attribute names
+var csvInvalidNameCharRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// csvAttributeNames converts the csv header names to the attribute names: the characters other than the letters,
+// digits and underscores such as the spaces are replaced with underscores, e.g. `zip code` to `zip_code`, and the
+// names starting with a digit are prefixed with an underscore. The names must be unique after the conversion.
+func csvAttributeNames(header []string) ([]string, error) {
+	names := make([]string, len(header))
+	seen := map[string]string{}
+	for i, h := range header {
+		name := csvInvalidNameCharRegexp.ReplaceAllString(strings.TrimSpace(h), "_")
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			name = "_" + name
+		}
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("the csv columns %q and %q have the same attribute name %s", other, h, name)
+		}
+		seen[name] = h
+		names[i] = name
+	}
+	return names, nil
+}
+
+// isCsvTypeHintRow reports whether every cell of the row is a known type hint.
+func isCsvTypeHintRow(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	for _, cell := range row {
+		if _, ok := csvTypeHints[strings.TrimSpace(cell)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// csvColumnType returns the kcl type of the column at index i. The type hint
+// wins if any, otherwise the type is inferred from all the values of the
+// column and widened on mixed values.
+func csvColumnType(rows [][]string, i int, hint string) string {
+	if hint != "" {
+		return hint
+	}
+	typ := ""
+	for _, row := range rows {
+		if i >= len(row) || row[i] == "" {
+			continue
+		}
+		typ = widenCsvType(typ, inferCsvValueType(row[i]))
+	}
+	if typ == "" {
+		// all the values are missing, so the type is unknown
+		return typAny
+	}
+	return typ
+}
+
+func inferCsvValueType(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return typInt
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return typFloat
+	}
+	if lower := strings.ToLower(value); lower == "true" || lower == "false" {
+		return typBool
+	}
+	return typStr
+}
+
+func widenCsvType(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case (a == typInt && b == typFloat) || (a == typFloat && b == typInt):
+		return typFloat
+	default:
+		return typStr
+	}
+}
+
+func csvColumnHasMissing(rows [][]string, i int) bool {
+	for _, row := range rows {
+		if i >= len(row) || row[i] == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// csvValue converts the raw csv cell to a go value of the kcl type. The cell which is not a value of the type, such
+// as `abc` in an int column declared by the type hint, is an error.
+func csvValue(value string, typ string) (interface{}, error) {
+	switch typ {
+	case typInt:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v, nil
+		}
+	case typFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v, nil
+		}
+	case typBool:
+		switch strings.ToLower(value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid %s value %q", typ, value)
+}
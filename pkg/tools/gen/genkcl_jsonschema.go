@@ -2,6 +2,7 @@ package gen
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"path/filepath"
 	"reflect"
@@ -12,7 +13,6 @@ import (
 
 	"github.com/iancoleman/strcase"
 	"kcl-lang.io/kcl-go/pkg/3rdparty/jsonschema"
-	"kcl-lang.io/kcl-go/pkg/logger"
 )
 
 type convertContext struct {
@@ -23,6 +23,7 @@ type convertContext struct {
 	// pathObjects is used to avoid infinite loop when converting recursive schema
 	// TODO: support recursive schema
 	pathObjects []*jsonschema.Schema
+	logger      Logger
 }
 
 type convertResult struct {
@@ -50,6 +51,7 @@ func (k *kclGenerator) genSchemaFromJsonSchema(w io.Writer, filename string, src
 		imports:     make(map[string]struct{}),
 		paths:       []string{},
 		pathObjects: []*jsonschema.Schema{},
+		logger:      k.logger(),
 	}
 	result := convertSchemaFromJsonSchema(&ctx, js,
 		strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
@@ -125,10 +127,10 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 					continue
 				}
 			}
-			typeList.Items = append(typeList.Items, jsonTypesToKclTypes(v.Vals))
+			typeList.Items = append(typeList.Items, jsonTypesToKclTypes(ctx.logger, v.Vals))
 		case *jsonschema.Items:
 			if !v.Single {
-				logger.GetLogger().Warningf("unsupported multiple items: %#v", v)
+				ctx.logger.Warn("unsupported multiple items", "items", fmt.Sprintf("%#v", v))
 				break
 			}
 			for i, val := range v.Schemas {
@@ -167,11 +169,11 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 			canConvert := true
 			if result.HasIndexSignature {
 				canConvert = false
-				logger.GetLogger().Warningf("failed to convert patternProperties: already has index signature.")
+				ctx.logger.Warn("failed to convert patternProperties: already has index signature")
 			}
 			if len(*v) != 1 {
 				canConvert = false
-				logger.GetLogger().Warningf("unsupported multiple patternProperties.")
+				ctx.logger.Warn("unsupported multiple patternProperties")
 			}
 			result.HasIndexSignature = true
 			result.IndexSignature = indexSignature{
@@ -203,7 +205,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 				unmarshalledVal := interface{}(nil)
 				err := json.Unmarshal(val, &unmarshalledVal)
 				if err != nil {
-					logger.GetLogger().Warningf("failed to unmarshal enum value: %s", err)
+					ctx.logger.Warn("failed to unmarshal enum value", "err", err)
 					continue
 				}
 				typeList.Items = append(typeList.Items, typeValue{
@@ -214,7 +216,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 			unmarshalledVal := interface{}(nil)
 			err := json.Unmarshal(*v, &unmarshalledVal)
 			if err != nil {
-				logger.GetLogger().Warningf("failed to unmarshal const value: %s", err)
+				ctx.logger.Warn("failed to unmarshal const value", "err", err)
 				continue
 			}
 			typeList.Items = []typeInterface{typeValue{Value: unmarshalledVal}}
@@ -224,7 +226,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 		case *jsonschema.Ref:
 			refSch := v.ResolveRef(ctx.rootSchema)
 			if refSch == nil || refSch.OrderedKeywords == nil {
-				logger.GetLogger().Warningf("failed to resolve ref: %s", v.Reference)
+				ctx.logger.Warn("failed to resolve ref", "ref", v.Reference)
 				continue
 			}
 			schs := []*jsonschema.Schema{refSch}
@@ -242,7 +244,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 						case *jsonschema.Ref:
 							refSch := v.ResolveRef(ctx.rootSchema)
 							if refSch == nil || refSch.OrderedKeywords == nil {
-								logger.GetLogger().Warningf("failed to resolve ref: %s.", v.Reference)
+								ctx.logger.Warn("failed to resolve ref", "ref", v.Reference)
 								continue
 							}
 							schs = append(schs, refSch)
@@ -261,7 +263,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 						case *jsonschema.MinItems:
 						case *jsonschema.Pattern:
 						default:
-							logger.GetLogger().Warningf("failed to merge ref: unsupported keyword %s. Paths: %s", key, strings.Join(ctx.paths, "/"))
+							ctx.logger.Warn("failed to merge ref: unsupported keyword", "keyword", key, "paths", strings.Join(ctx.paths, "/"))
 						}
 					}
 				}
@@ -323,7 +325,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 		case *jsonschema.MultipleOf:
 			vInt := int(*v)
 			if float64(vInt) != float64(*v) {
-				logger.GetLogger().Warningf("unsupported multipleOf value: %f", *v)
+				ctx.logger.Warn("unsupported multipleOf value", "multipleOf", *v)
 				continue
 			}
 			result.Validations = append(result.Validations, validation{
@@ -367,7 +369,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 						case *jsonschema.Ref:
 							refSch := v.ResolveRef(ctx.rootSchema)
 							if refSch == nil || refSch.OrderedKeywords == nil {
-								logger.GetLogger().Warningf("failed to resolve ref: %s", v.Reference)
+								ctx.logger.Warn("failed to resolve ref", "ref", v.Reference)
 								continue
 							}
 							schs = append(schs, refSch)
@@ -380,7 +382,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 							reqs = append(reqs, *v...)
 							s.Keywords[key] = &reqs
 						default:
-							logger.GetLogger().Warningf("failed to merge allOf: unsupported keyword %s", key)
+							ctx.logger.Warn("failed to merge allOf: unsupported keyword", "keyword", key)
 						}
 					}
 				}
@@ -389,7 +391,7 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 				return jsonschema.GetKeywordOrder(s.OrderedKeywords[i]) < jsonschema.GetKeywordOrder(s.OrderedKeywords[j])
 			})
 		default:
-			logger.GetLogger().Warningf("unknown Keyword: %s", k)
+			ctx.logger.Warn("unknown keyword", "keyword", k)
 		}
 	}
 
@@ -424,15 +426,15 @@ func convertSchemaFromJsonSchema(ctx *convertContext, s *jsonschema.Schema, name
 	return result
 }
 
-func jsonTypesToKclTypes(t []string) typeInterface {
+func jsonTypesToKclTypes(l Logger, t []string) typeInterface {
 	var kclTypes typeUnion
 	for _, v := range t {
-		kclTypes.Items = append(kclTypes.Items, jsonTypeToKclType(v))
+		kclTypes.Items = append(kclTypes.Items, jsonTypeToKclType(l, v))
 	}
 	return kclTypes
 }
 
-func jsonTypeToKclType(t string) typeInterface {
+func jsonTypeToKclType(l Logger, t string) typeInterface {
 	switch t {
 	case "string":
 		return typePrimitive(typStr)
@@ -443,7 +445,7 @@ func jsonTypeToKclType(t string) typeInterface {
 	case "number":
 		return typePrimitive(typFloat)
 	default:
-		logger.GetLogger().Warningf("unknown type: %s", t)
+		l.Warn("unknown type", "type", t)
 		return typePrimitive(typStr)
 	}
 }
@@ -0,0 +1,364 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const kclIndent = "    "
+
+// kclFileName returns the path of the KCL file of the package relative to the target directory, which is `main.k`
+// for the root package and `<path>/<name>.k` for the sub packages such as `a/b/b.k` for `a.b`
+func kclFileName(pkgName string) string {
+	if pkgName == "" {
+		return "main.k"
+	}
+	dir := filepath.Join(strings.Split(pkgName, ".")...)
+	return filepath.Join(dir, pkgName[strings.LastIndex(pkgName, ".")+1:]+".k")
+}
+
+// ExportKclSchemas re-emits the schemas of the spec as the canonical KCL declarations, which normalizes the KCL
+// sources: the schemas and attributes are sorted by name, the reopened schemas are merged into one declaration, the
+// docstrings are written in the `Attributes` and `Examples` sections, and the defaults, decorators and checks are
+// formatted consistently. The returned map is from the file path of each package to the file content.
+func ExportKclSchemas(spec *SwaggerV2Spec) map[string][]byte {
+	packages := map[string][]*KclOpenAPIType{}
+	for _, id := range getSortedKeys(spec.Definitions) {
+		schema := spec.Definitions[id]
+		pkgName := schema.KclExtensions.XKclModelType.Import.Package
+		packages[pkgName] = append(packages[pkgName], schema)
+	}
+	files := map[string][]byte{}
+	for pkgName, schemas := range packages {
+		sort.Slice(schemas, func(i, j int) bool {
+			return schemas[i].KclExtensions.XKclModelType.Type < schemas[j].KclExtensions.XKclModelType.Type
+		})
+		w := &kclSchemaWriter{spec: spec, pkgName: pkgName, imports: map[string]string{}}
+		var body bytes.Buffer
+		for i, schema := range schemas {
+			if i > 0 {
+				body.WriteString("\n")
+			}
+			w.writeSchema(&body, schema)
+		}
+		var buf bytes.Buffer
+		for _, path := range getSortedKeys(w.imports) {
+			if alias := w.imports[path]; alias != path[strings.LastIndex(path, ".")+1:] {
+				fmt.Fprintf(&buf, "import %s as %s\n", path, alias)
+			} else {
+				fmt.Fprintf(&buf, "import %s\n", path)
+			}
+		}
+		if len(w.imports) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.Write(body.Bytes())
+		files[kclFileName(pkgName)] = buf.Bytes()
+	}
+	return files
+}
+
+// kclSchemaWriter writes the schemas of a package, and collects the imports of the referenced packages by path
+type kclSchemaWriter struct {
+	spec    *SwaggerV2Spec
+	pkgName string
+	imports map[string]string
+}
+
+// writeSchema writes the declaration of the schema with the decorators, the arguments, the base schema, the protocol
+// of the mixin, the docstring, the mixins, the attributes and the checks
+func (w *kclSchemaWriter) writeSchema(buf *bytes.Buffer, schema *KclOpenAPIType) {
+	ext := schema.KclExtensions
+	for _, d := range ext.XKclDecorators {
+		buf.WriteString(kclDecorator(d) + "\n")
+	}
+	keyword := "schema"
+	if ext.XKclKind != "" {
+		keyword = ext.XKclKind
+	}
+	fmt.Fprintf(buf, "%s %s", keyword, ext.XKclModelType.Type)
+	if ext.XKclSchemaArgs != "" {
+		fmt.Fprintf(buf, "[%s]", ext.XKclSchemaArgs)
+	}
+	if ext.XKclBaseSchema != "" {
+		fmt.Fprintf(buf, "(%s)", w.schemaName(Ref2SchemaId(ext.XKclBaseSchema), ""))
+	}
+	if ext.XKclProtocol != "" {
+		fmt.Fprintf(buf, " for %s", w.schemaName(Ref2SchemaId(ext.XKclProtocol), ""))
+	}
+	buf.WriteString(":\n")
+	w.writeDocstring(buf, schema)
+	if len(ext.XKclMixins) > 0 {
+		mixins := make([]string, len(ext.XKclMixins))
+		for i, ref := range ext.XKclMixins {
+			mixins[i] = w.schemaName(Ref2SchemaId(ref), "")
+		}
+		fmt.Fprintf(buf, "%smixin [%s]\n", kclIndent, strings.Join(mixins, ", "))
+	}
+
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	names := w.declaredAttributes(schema)
+	for _, name := range names {
+		prop := schema.Properties[name]
+		if prop.KclExtensions != nil {
+			for _, d := range prop.KclExtensions.XKclDecorators {
+				buf.WriteString(kclIndent + kclDecorator(d) + "\n")
+			}
+		}
+		optional := ""
+		if !required[name] {
+			optional = "?"
+		}
+		fmt.Fprintf(buf, "%s%s%s: %s", kclIndent, name, optional, w.attributeType(prop))
+		if value := kclAttributeDefault(prop); value != "" {
+			fmt.Fprintf(buf, " = %s", kclReindent(value, kclIndent))
+		}
+		buf.WriteString("\n")
+	}
+	if len(names) == 0 && len(ext.XKclMixins) == 0 && len(ext.XKclChecks) == 0 && schema.Description == "" && len(schema.Examples) == 0 {
+		buf.WriteString(kclIndent + "pass\n")
+	}
+
+	if len(ext.XKclChecks) > 0 {
+		buf.WriteString("\n" + kclIndent + "check:\n")
+		for _, check := range ext.XKclChecks {
+			buf.WriteString(kclIndent + kclIndent + check.Expr)
+			if check.Condition != "" {
+				buf.WriteString(" if " + check.Condition)
+			}
+			if check.Message != "" {
+				buf.WriteString(", " + strconv.Quote(check.Message))
+			}
+			buf.WriteString("\n")
+		}
+	}
+}
+
+// writeDocstring writes the docstring of the schema with the description, the `Attributes` section of the attribute
+// descriptions and the `Examples` section. The lines are trimmed, and the consecutive blank lines are merged
+func (w *kclSchemaWriter) writeDocstring(buf *bytes.Buffer, schema *KclOpenAPIType) {
+	var sections []string
+	if doc := normalizeDocLines(schema.Description, ""); doc != "" {
+		sections = append(sections, doc)
+	}
+	var attributes []string
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	for _, name := range w.declaredAttributes(schema) {
+		prop := schema.Properties[name]
+		doc := normalizeDocLines(prop.Description, kclIndent)
+		if doc == "" {
+			continue
+		}
+		line := fmt.Sprintf("%s : %s", name, w.attributeType(prop))
+		if value := kclAttributeDefault(prop); value != "" && !strings.Contains(value, "\n") {
+			line += ", default is " + value
+		}
+		if required[name] {
+			line += ", required"
+		} else {
+			line += ", optional"
+		}
+		attributes = append(attributes, line+"\n"+doc)
+	}
+	if len(attributes) > 0 {
+		sections = append(sections, "Attributes\n----------\n"+strings.Join(attributes, "\n"))
+	}
+	var examples []string
+	for _, name := range getSortedKeys(schema.Examples) {
+		if value := normalizeDocLines(schema.Examples[name].Value, ""); value != "" {
+			examples = append(examples, value)
+		}
+	}
+	if len(examples) > 0 {
+		sections = append(sections, "Examples\n--------\n"+strings.Join(examples, "\n\n"))
+	}
+	if len(sections) == 0 {
+		return
+	}
+	buf.WriteString(kclIndent + `"""` + "\n")
+	for _, line := range strings.Split(strings.Join(sections, "\n\n"), "\n") {
+		if line == "" {
+			buf.WriteString("\n")
+		} else {
+			buf.WriteString(kclIndent + line + "\n")
+		}
+	}
+	buf.WriteString(kclIndent + `"""` + "\n")
+}
+
+// declaredAttributes returns the names of the attributes declared by the schema sorted by name. The attributes of
+// the mixins are declared by the mixins, unless the schema body declares them as well
+func (w *kclSchemaWriter) declaredAttributes(schema *KclOpenAPIType) []string {
+	mixed := map[string]bool{}
+	for _, ref := range schema.KclExtensions.XKclMixins {
+		if mixin, ok := w.spec.Definitions[Ref2SchemaId(ref)]; ok {
+			for name := range mixin.Properties {
+				mixed[name] = !containsString(schema.KclExtensions.XKclAttributeOrder, name)
+			}
+		}
+	}
+	var names []string
+	for _, name := range getSortedKeys(schema.Properties) {
+		if !mixed[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// normalizeDocLines removes the common indentation and the trailing spaces of the lines, merges the consecutive
+// blank lines and indents the lines with the indent
+func normalizeDocLines(doc string, indent string) string {
+	lines := strings.Split(strings.Trim(doc, "\n"), "\n")
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); common < 0 || n < common {
+			common = n
+		}
+	}
+	var result []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(result) > 0 && result[len(result)-1] != "" {
+				result = append(result, "")
+			}
+			continue
+		}
+		result = append(result, indent+line[common:])
+	}
+	return strings.TrimRight(strings.Join(result, "\n"), "\n")
+}
+
+// attributeType returns the type expression of the attribute. The nullable types accept None
+func (w *kclSchemaWriter) attributeType(tpe *KclOpenAPIType) string {
+	expr := w.typeExpr(tpe)
+	if tpe.Nullable {
+		expr += " | None"
+	}
+	return expr
+}
+
+// typeExpr returns the canonical KCL type expression of the type, and records the imports of the packages of the
+// referenced schemas
+func (w *kclSchemaWriter) typeExpr(tpe *KclOpenAPIType) string {
+	if tpe.Ref != "" {
+		alias := ""
+		if tpe.KclExtensions != nil {
+			alias = tpe.KclExtensions.XKclImportAlias
+		}
+		return w.schemaName(Ref2SchemaId(tpe.Ref), alias)
+	}
+	if tpe.ReadOnly && len(tpe.Enum) > 0 {
+		return kclStringQuotes(tpe.Enum[0])
+	}
+	switch tpe.Type {
+	case String:
+		return typStr
+	case Integer:
+		if tpe.Format == NumberMultiplier {
+			w.imports["units"] = "units"
+			return string(NumberMultiplier)
+		}
+		return typInt
+	case Number:
+		return typFloat
+	case Bool:
+		return typBool
+	case Array:
+		return fmt.Sprintf("[%s]", w.typeExpr(tpe.Items))
+	case Object:
+		if tpe.AdditionalProperties != nil {
+			key := "str"
+			if tpe.KclExtensions != nil && tpe.KclExtensions.XKclDictKeyType != nil {
+				key = w.typeExpr(tpe.KclExtensions.XKclDictKeyType)
+			}
+			return fmt.Sprintf("{%s: %s}", key, w.typeExpr(tpe.AdditionalProperties))
+		}
+		if tpe.KclExtensions != nil && len(tpe.KclExtensions.XKclUnionTypes) > 0 {
+			members := make([]string, len(tpe.KclExtensions.XKclUnionTypes))
+			for i, t := range tpe.KclExtensions.XKclUnionTypes {
+				members[i] = w.typeExpr(t)
+			}
+			return strings.Join(members, " | ")
+		}
+	}
+	return typAny
+}
+
+// schemaName returns the name of the schema referenced from the package, which is qualified by the import alias of
+// the package of the schema if it is in another package
+func (w *kclSchemaWriter) schemaName(id string, alias string) string {
+	i := strings.LastIndex(id, ".")
+	if i < 0 || id[:i] == w.pkgName {
+		return id[i+1:]
+	}
+	path := id[:i]
+	if alias == "" {
+		alias = path[strings.LastIndex(path, ".")+1:]
+	}
+	w.imports[path] = alias
+	return alias + "." + id[i+1:]
+}
+
+// kclAttributeDefault returns the default value of the attribute. The literal types have no default
+func kclAttributeDefault(tpe *KclOpenAPIType) string {
+	if tpe.Default == "" || (tpe.ReadOnly && len(tpe.Enum) > 0 && tpe.Default == tpe.Enum[0]) {
+		return ""
+	}
+	return kclStringQuotes(strings.TrimSpace(tpe.Default))
+}
+
+// kclStringQuotes returns the literal with the single quotes of the string literals replaced by the double quotes
+func kclStringQuotes(lit string) string {
+	if len(lit) >= 2 && strings.HasPrefix(lit, "'") && strings.HasSuffix(lit, "'") && !strings.ContainsAny(lit[1:len(lit)-1], `'"\`) {
+		return `"` + lit[1:len(lit)-1] + `"`
+	}
+	return lit
+}
+
+// kclReindent indents the continuation lines of the multi-line expression by the bracket depth, starting from the
+// indent of the statement
+func kclReindent(expr string, indent string) string {
+	lines := strings.Split(expr, "\n")
+	depth := bracketDepth(lines[0])
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		level := depth
+		if strings.HasPrefix(line, "}") || strings.HasPrefix(line, "]") || strings.HasPrefix(line, ")") {
+			level--
+		}
+		if level < 0 {
+			level = 0
+		}
+		lines[i] = indent + strings.Repeat(kclIndent, level) + line
+		depth += bracketDepth(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// kclDecorator returns the decorator expression such as `@deprecated(version="1.16", strict=False)`. The keyword
+// arguments are sorted by name
+func kclDecorator(d *XKclDecorator) string {
+	args := append([]string{}, d.Arguments...)
+	for _, k := range getSortedKeys(d.Keywords) {
+		args = append(args, fmt.Sprintf("%s=%s", k, kclStringQuotes(d.Keywords[k])))
+	}
+	if len(args) == 0 {
+		return "@" + d.Name
+	}
+	return fmt.Sprintf("@%s(%s)", d.Name, strings.Join(args, ", "))
+}
@@ -2,13 +2,13 @@ package gen
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"reflect"
 	"sort"
 	"strconv"
 
 	"github.com/iancoleman/strcase"
-	"kcl-lang.io/kcl-go/pkg/logger"
 )
 
 type tfSchema struct {
@@ -46,6 +46,7 @@ type tfAttribute struct {
 type tfConvertContext struct {
 	resultMap  map[string]schema
 	attrKeyNow string
+	logger     Logger
 }
 
 func (k *kclGenerator) genSchemaFromTerraformSchema(w io.Writer, filename string, src interface{}) error {
@@ -61,6 +62,7 @@ func (k *kclGenerator) genSchemaFromTerraformSchema(w io.Writer, filename string
 	// convert terraform schema to kcl schema
 	ctx := &tfConvertContext{
 		resultMap: make(map[string]schema),
+		logger:    k.logger(),
 	}
 	for _, providerSchema := range tfSch.ProviderSchemas {
 		convertSchemaFromTFSchema(ctx, providerSchema)
@@ -154,7 +156,7 @@ func convertTFNestedSchema(ctx *tfConvertContext, tfSchema map[string]interface{
 func tfTypeToKclType(ctx *tfConvertContext, t interface{}) typeInterface {
 	switch t := t.(type) {
 	case string:
-		return jsonTypeToKclType(t)
+		return jsonTypeToKclType(ctx.logger, t)
 	case []interface{}:
 		switch t[0] {
 		case "list":
@@ -169,11 +171,11 @@ func tfTypeToKclType(ctx *tfConvertContext, t interface{}) typeInterface {
 			// todo
 			return typePrimitive(typAny)
 		default:
-			logger.GetLogger().Warningf("unknown type: %#v", t)
+			ctx.logger.Warn("unknown type", "type", fmt.Sprintf("%#v", t))
 			return typePrimitive(typAny)
 		}
 	default:
-		logger.GetLogger().Warningf("unknown type: %#v", t)
+		ctx.logger.Warn("unknown type", "type", fmt.Sprintf("%#v", t))
 		return typePrimitive(typAny)
 	}
 }
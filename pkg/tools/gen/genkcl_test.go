@@ -154,6 +154,17 @@ func TestGenKclFromJsonSchema(t *testing.T) {
 	}
 }
 
+func TestGenKclLogger(t *testing.T) {
+	const input = `{"type": "object", "properties": {"ratio": {"type": "number", "multipleOf": 0.5}}}`
+	logger := &recordLogger{}
+	var buf bytes.Buffer
+	err := GenKcl(&buf, "config.json", input, &GenKclOptions{Mode: ModeJsonSchema, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert2.Contains(t, logger.events, "warn unsupported multipleOf value multipleOf=0.5")
+}
+
 func TestGenKclFromTerraform(t *testing.T) {
 	input := filepath.Join("testdata", "terraform", "schema.json")
 	expectFilepath := filepath.Join("testdata", "terraform", "expect.k")
@@ -182,6 +193,18 @@ func TestGenKclFromJson(t *testing.T) {
 	assert2.Equal(t, expect, string(bytes.ReplaceAll(result, []byte("\r\n"), []byte("\n"))))
 }
 
+func TestGenKclFromJsonNull(t *testing.T) {
+	var buf bytes.Buffer
+	err := GenKcl(&buf, "input.json", []byte(`{"name": "nginx", "image": null, "labels": {"tier": null}, "ports": [80, null]}`), &GenKclOptions{Mode: ModeJson})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the null values are typed any
+	assert2.Contains(t, buf.String(), "    name = \"nginx\"\n    image = None  # any\n")
+	assert2.Contains(t, buf.String(), "        tier = None  # any\n")
+	assert2.Contains(t, buf.String(), "        80\n        None\n")
+}
+
 func TestGenKclFromYaml(t *testing.T) {
 	type testCase struct {
 		name   string
@@ -256,6 +279,52 @@ func TestGenKclFromMultipleResourceYaml(t *testing.T) {
 	}
 }
 
+func TestGenKclFromCsv(t *testing.T) {
+	type testCase struct {
+		name   string
+		input  string
+		expect string
+	}
+	var cases []testCase
+
+	casesPath := filepath.Join("testdata", "csv")
+	caseFiles, err := os.ReadDir(casesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, caseFile := range caseFiles {
+		input := filepath.Join(casesPath, caseFile.Name(), "input.csv")
+		expectFilepath := filepath.Join(casesPath, caseFile.Name(), "expect.k")
+		cases = append(cases, testCase{
+			name:   caseFile.Name(),
+			input:  input,
+			expect: readFileString(t, expectFilepath),
+		})
+	}
+
+	for _, testcase := range cases {
+		t.Run(testcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := GenKcl(&buf, testcase.input, nil, &GenKclOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := buf.Bytes()
+			assert2.Equal(t, testcase.expect, string(bytes.ReplaceAll(result, []byte("\r\n"), []byte("\n"))))
+		})
+	}
+}
+
+func TestGenKclFromCsvErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := GenKcl(&buf, "input.csv", []byte("name,age\nstr,int\nAlice,30\nBob,abc\n"), &GenKclOptions{Mode: ModeCsv})
+	assert2.EqualError(t, err, `row 4, column age: invalid int value "abc"`)
+
+	err = GenKcl(&buf, "input.csv", []byte("zip code,zip-code\n1,2\n"), &GenKclOptions{Mode: ModeCsv})
+	assert2.EqualError(t, err, `the csv columns "zip code" and "zip-code" have the same attribute name zip_code`)
+}
+
 type TestData = data
 
 func TestGenKclFromJsonAndImports(t *testing.T) {
@@ -374,3 +443,164 @@ func readFileString(t testing.TB, p string) (content string) {
 	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
 	return string(data)
 }
+
+func TestExportKclSchemas(t *testing.T) {
+	port := &KclOpenAPIType{Type: Integer, Format: Int64, Default: "80", Description: "  The listening port.  \n\n\n  Defaults to 80."}
+	port.KclExtensions = &KclExtensions{XKclDecorators: XKclDecorators{{Name: "deprecated", Keywords: map[string]string{"version": "'1.2'"}}}}
+	server := newTestSchema("Server", "Server is a\nlong-running service.  ", []string{"name", "port"}, map[string]*KclOpenAPIType{
+		"port":     port,
+		"name":     {Type: String, Default: "'main'"},
+		"labels":   {Type: Object, AdditionalProperties: &KclOpenAPIType{Type: String}, KclExtensions: &KclExtensions{XKclDictKeyType: &KclOpenAPIType{Type: String}}},
+		"protocol": {Type: Object, Default: `"TCP"`, KclExtensions: &KclExtensions{XKclUnionTypes: []*KclOpenAPIType{{Type: String, ReadOnly: true, Enum: []string{`"TCP"`}, Default: `"TCP"`}, {Type: String, ReadOnly: true, Enum: []string{`"UDP"`}, Default: `"UDP"`}}}},
+		"endpoint": {Ref: SchemaId2Ref("net.Endpoint"), Nullable: true},
+		"memory":   {Type: Integer, Format: NumberMultiplier, Default: "1Gi"},
+	})
+	server.KclExtensions.XKclBaseSchema = SchemaId2Ref("Base")
+	server.KclExtensions.XKclChecks = []*XKclCheck{{Expr: "1 <= port <= 65535", Message: "invalid port"}, {Expr: "len(name) > 0", Condition: "name"}}
+	server.Examples = map[string]KclExample{"Default example": {Value: "server = Server {\n    name = \"web\"\n}"}}
+	endpoint := newTestSchema("Endpoint", "", []string{"host"}, map[string]*KclOpenAPIType{
+		"host": {Type: String},
+		"tags": {Type: Array, Items: &KclOpenAPIType{Type: String}, Default: "[\n'a',\n'b'\n]"},
+	})
+	endpoint.KclExtensions.XKclModelType.Import.Package = "net"
+	base := newTestSchema("Base", "", nil, nil)
+	spec := &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"Server": server, "Base": base, "net.Endpoint": endpoint}}
+
+	files := ExportKclSchemas(spec)
+	assert2.Equal(t, []string{"main.k", filepath.Join("net", "net.k")}, getSortedKeys(files))
+	assert2.Equal(t, `import net
+import units
+
+schema Base:
+    pass
+
+schema Server(Base):
+    """
+    Server is a
+    long-running service.
+
+    Attributes
+    ----------
+    port : int, default is 80, required
+        The listening port.
+
+        Defaults to 80.
+
+    Examples
+    --------
+    server = Server {
+        name = "web"
+    }
+    """
+    endpoint?: net.Endpoint | None
+    labels?: {str: str}
+    memory?: units.NumberMultiplier = 1Gi
+    name: str = "main"
+    @deprecated(version="1.2")
+    port: int = 80
+    protocol?: "TCP" | "UDP" = "TCP"
+
+    check:
+        1 <= port <= 65535, "invalid port"
+        len(name) > 0 if name
+`, string(files["main.k"]))
+	assert2.Equal(t, `schema Endpoint:
+    host: str
+    tags?: [str] = [
+        'a',
+        'b'
+    ]
+`, string(files[filepath.Join("net", "net.k")]))
+
+	// the output is stable on re-run
+	assert2.Equal(t, files, ExportKclSchemas(spec))
+
+	g := newTestGenContext(t, Kcl)
+	rendered := renderTestDoc(t, g, spec)
+	assert2.Equal(t, string(files["main.k"]), rendered["main.k"])
+	assert2.Equal(t, string(files[filepath.Join("net", "net.k")]), rendered["net/net.k"])
+
+	// the mixins with the protocols and the schema arguments
+	protocol := newTestSchema("NameProtocol", "", []string{"name"}, map[string]*KclOpenAPIType{"name": {Type: String}})
+	protocol.KclExtensions.XKclKind = ProtocolKind
+	protocol.KclExtensions.XKclModelType.Import.Package = "mixins"
+	mixin := newTestSchema("FullNameMixin", "", nil, map[string]*KclOpenAPIType{"fullName": {Type: String}})
+	mixin.KclExtensions.XKclKind = MixinKind
+	mixin.KclExtensions.XKclProtocol = SchemaId2Ref("mixins.NameProtocol")
+	mixin.KclExtensions.XKclModelType.Import.Package = "mixins"
+	person := newTestSchema("Person", "", []string{"name"}, map[string]*KclOpenAPIType{
+		"name":     {Type: String},
+		"fullName": {Type: String},
+	})
+	person.KclExtensions.XKclMixins = []string{SchemaId2Ref("mixins.FullNameMixin")}
+	person.KclExtensions.XKclAttributeOrder = []string{"name"}
+	person.KclExtensions.XKclSchemaArgs = "prefix: str"
+	spec = &SwaggerV2Spec{Definitions: map[string]*KclOpenAPIType{"mixins.NameProtocol": protocol, "mixins.FullNameMixin": mixin, "Person": person}}
+	files = ExportKclSchemas(spec)
+	assert2.Equal(t, `mixin FullNameMixin for NameProtocol:
+    fullName?: str
+
+protocol NameProtocol:
+    name: str
+`, string(files[filepath.Join("mixins", "mixins.k")]))
+	// the attributes of the mixins are not redeclared
+	assert2.Equal(t, `import mixins
+
+schema Person[prefix: str]:
+    mixin [mixins.FullNameMixin]
+    name: str
+`, string(files["main.k"]))
+}
+
+func TestExportKclSchemasRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	assert2.NoError(t, os.MkdirAll(filepath.Join(dir, "net"), 0755))
+	assert2.NoError(t, os.WriteFile(filepath.Join(dir, "kcl.mod"), []byte("[package]\nname = \"app\"\n"), 0644))
+	assert2.NoError(t, os.WriteFile(filepath.Join(dir, "net", "endpoint.k"), []byte("schema Endpoint:\n    host: str\n"), 0644))
+	assert2.NoError(t, os.WriteFile(filepath.Join(dir, "server.k"), []byte(`import net
+
+schema Server:
+    """Server is a long-running service.
+
+    Attributes
+    ----------
+    port : int, default is 80, required
+        The listening port.
+    """
+    port: int = 80
+    name: str
+    endpoint?: net.Endpoint
+    protocol: "TCP" | "UDP" = 'TCP'
+
+    check:
+        1 <= port <= 65535, "invalid port"
+`), 0644))
+	assert2.NoError(t, os.WriteFile(filepath.Join(dir, "person.k"), []byte(`protocol NameProtocol:
+    name: str
+
+mixin GreetingMixin for NameProtocol:
+    greeting?: str
+
+schema Person[prefix: str]:
+    mixin [GreetingMixin]
+    name: str
+`), 0644))
+
+	export := func(pkgPath string) map[string][]byte {
+		spec, err := ExportSwaggerV2Spec(pkgPath)
+		assert2.NoError(t, err)
+		return ExportKclSchemas(spec)
+	}
+	first := export(dir)
+	assert2.Contains(t, string(first["main.k"]), "mixin GreetingMixin for NameProtocol:\n")
+	assert2.Contains(t, string(first["main.k"]), "schema Person[prefix: str]:\n    mixin [GreetingMixin]\n    name: str\n")
+
+	normalized := t.TempDir()
+	assert2.NoError(t, os.WriteFile(filepath.Join(normalized, "kcl.mod"), []byte("[package]\nname = \"app\"\n"), 0644))
+	for path, content := range first {
+		assert2.NoError(t, os.MkdirAll(filepath.Join(normalized, filepath.Dir(path)), 0755))
+		assert2.NoError(t, os.WriteFile(filepath.Join(normalized, path), content, 0644))
+	}
+	// the normalized sources are normalized to themselves
+	assert2.Equal(t, first, export(normalized))
+}
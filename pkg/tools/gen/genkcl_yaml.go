@@ -47,6 +47,9 @@ func convertKclFromYaml(yamlData *yaml.MapSlice) []data {
 				}
 			}
 			result = append(result, data{Key: key, Value: vals})
+		case nil:
+			// the type of the null values is unknown
+			result = append(result, data{Key: key, Value: value, Type: typAny})
 		default:
 			result = append(result, data{Key: key, Value: value})
 		}
@@ -19,6 +19,18 @@ const (
 	ExtensionKclDecorators  = "x-kcl-decorators"
 	ExtensionKclUnionTypes  = "x-kcl-union-types"
 	ExtensionKclDictKeyType = "x-kcl-dict-key-type"
+	ExtensionKclChecks      = "x-kcl-checks"
+	ExtensionKclBaseSchema  = "x-kcl-base-schema"
+	ExtensionKclImportAlias = "x-kcl-import-alias"
+	ExtensionKclAttrGroups  = "x-kcl-attribute-groups"
+	ExtensionKclUnit        = "x-kcl-unit"
+	ExtensionKclKind        = "x-kcl-kind"
+	ExtensionKclTypeExpr    = "x-kcl-type-expr"
+	ExtensionKclMixins      = "x-kcl-mixins"
+	ExtensionKclAttrOrder   = "x-kcl-attribute-order"
+	ExtensionKclEnum        = "x-kcl-enum"
+	ExtensionKclProtocol    = "x-kcl-protocol"
+	ExtensionKclSchemaArgs  = "x-kcl-schema-args"
 )
 
 // ExportOpenAPIV3Spec exports open api v3 spec of a kcl package
@@ -40,6 +52,7 @@ func ExportOpenAPITypeToSchema(ty *KclOpenAPIType) *openapi3.SchemaRef {
 			Default:     ty.Default,
 			Enum:        ty.GetAnyEnum(),
 			ReadOnly:    ty.ReadOnly,
+			Nullable:    ty.Nullable,
 			Description: ty.Description,
 			Properties:  make(openapi3.Schemas),
 			Required:    ty.Required,
@@ -58,6 +71,20 @@ func ExportOpenAPITypeToSchema(ty *KclOpenAPIType) *openapi3.SchemaRef {
 			Schema: ExportOpenAPITypeToSchema(ty.AdditionalProperties),
 		}
 	}
+	if ty.MinItems != nil {
+		s.Value.MinItems = uint64(*ty.MinItems)
+	}
+	if ty.MaxItems != nil {
+		max := uint64(*ty.MaxItems)
+		s.Value.MaxItems = &max
+	}
+	if ty.MinProperties != nil {
+		s.Value.MinProps = uint64(*ty.MinProperties)
+	}
+	if ty.MaxProperties != nil {
+		max := uint64(*ty.MaxProperties)
+		s.Value.MaxProps = &max
+	}
 	if ty.Examples != nil && len(ty.Examples) > 0 {
 		s.Value.Example = ty.Examples
 	}
@@ -71,6 +98,11 @@ func ExportOpenAPITypeToSchema(ty *KclOpenAPIType) *openapi3.SchemaRef {
 
 // ExportSwaggerV2Spec extracts the swagger v2 representation of a kcl package
 func ExportSwaggerV2Spec(pkgPath string) (*SwaggerV2Spec, error) {
+	return exportSwaggerV2Spec(pkgPath, defaultLogger)
+}
+
+// exportSwaggerV2Spec extracts the swagger v2 representation of a kcl package, logging the exported schemas
+func exportSwaggerV2Spec(pkgPath string, logger Logger) (*SwaggerV2Spec, error) {
 	pkg, err := kpm.GetKclPackage(pkgPath)
 	if err != nil {
 		return nil, fmt.Errorf("filePath is not a KCL package: %s", err)
@@ -95,7 +127,7 @@ func ExportSwaggerV2Spec(pkgPath string) (*SwaggerV2Spec, error) {
 		for _, t := range p {
 			id := SchemaId(packagePath, t.KclType)
 			spec.Definitions[id] = GetKclOpenAPIType(packagePath, t.KclType, false)
-			fmt.Printf("exporting openAPI spec from schema %s\n", id)
+			logger.Info("exporting openAPI spec from schema", "schema", id)
 		}
 	}
 	return spec, nil
@@ -192,6 +224,11 @@ type KclOpenAPIType struct {
 	Examples             map[string]KclExample      `json:"examples,omitempty"`             // examples
 	ExternalDocs         string                     `json:"externalDocs,omitempty"`         // externalDocs
 	Ref                  string                     `json:"ref,omitempty"`                  // reference to schema path
+	Nullable             bool                       `json:"nullable,omitempty"`             // whether the value may be None
+	MinItems             *int                       `json:"minItems,omitempty"`             // min length of the list
+	MaxItems             *int                       `json:"maxItems,omitempty"`             // max length of the list
+	MinProperties        *int                       `json:"minProperties,omitempty"`        // min length of the dict
+	MaxProperties        *int                       `json:"maxProperties,omitempty"`        // max length of the dict
 	*KclExtensions                                  // x-kcl- extensions
 }
 
@@ -223,14 +260,27 @@ type KclExample struct {
 	Summary     string `json:"summary,omitempty"`
 	Description string `json:"description,omitempty"`
 	Value       string `json:"value,omitempty"`
+	Evaluated   string `json:"x-kcl-evaluated,omitempty"` // the evaluated result of the example in YAML
 }
 
 // KclExtensions defines all the KCL specific extensions patched to OpenAPI
 type KclExtensions struct {
-	XKclModelType   *XKclModelType    `json:"x-kcl-type,omitempty"`
-	XKclDecorators  XKclDecorators    `json:"x-kcl-decorators,omitempty"`
-	XKclUnionTypes  []*KclOpenAPIType `json:"x-kcl-union-types,omitempty"`
-	XKclDictKeyType *KclOpenAPIType   `json:"x-kcl-dict-key-type,omitempty"` // dict key type
+	XKclModelType       *XKclModelType        `json:"x-kcl-type,omitempty"`
+	XKclDecorators      XKclDecorators        `json:"x-kcl-decorators,omitempty"`
+	XKclUnionTypes      []*KclOpenAPIType     `json:"x-kcl-union-types,omitempty"`
+	XKclDictKeyType     *KclOpenAPIType       `json:"x-kcl-dict-key-type,omitempty"`    // dict key type
+	XKclChecks          []*XKclCheck          `json:"x-kcl-checks,omitempty"`           // schema check expressions
+	XKclBaseSchema      string                `json:"x-kcl-base-schema,omitempty"`      // reference to the base schema
+	XKclImportAlias     string                `json:"x-kcl-import-alias,omitempty"`     // import alias of the referenced schema
+	XKclAttributeGroups []*XKclAttributeGroup `json:"x-kcl-attribute-groups,omitempty"` // attribute groups derived from the checks
+	XKclUnit            string                `json:"x-kcl-unit,omitempty"`             // unit suffix of the quantity default
+	XKclKind            string                `json:"x-kcl-kind,omitempty"`             // mixin or protocol, empty for the schemas
+	XKclTypeExpr        string                `json:"x-kcl-type-expr,omitempty"`        // type expression of the attribute as authored
+	XKclMixins          []string              `json:"x-kcl-mixins,omitempty"`           // references to the mixins of the schema
+	XKclAttributeOrder  []string              `json:"x-kcl-attribute-order,omitempty"`  // attributes declared in the schema body in the source order
+	XKclEnum            string                `json:"x-kcl-enum,omitempty"`             // id of the type alias of the literal union type
+	XKclProtocol        string                `json:"x-kcl-protocol,omitempty"`         // reference to the protocol of the mixin
+	XKclSchemaArgs      string                `json:"x-kcl-schema-args,omitempty"`      // arguments of the schema as authored
 }
 
 // XKclModelType defines the `x-kcl-type` extension
@@ -260,10 +310,15 @@ func (tpe *KclOpenAPIType) GetKclTypeName(omitAny bool, addLink bool, escapeHtml
 	if tpe.Ref != "" {
 		schemaId := Ref2SchemaId(tpe.Ref)
 		schemaName := schemaId[strings.LastIndex(schemaId, ".")+1:]
+		// display the reference with the import alias as it is written in the source code, and the link stays the schema anchor
+		displayName := schemaName
+		if tpe.KclExtensions != nil && tpe.KclExtensions.XKclImportAlias != "" {
+			displayName = fmt.Sprintf("%s.%s", tpe.KclExtensions.XKclImportAlias, schemaName)
+		}
 		if addLink {
-			return fmt.Sprintf("[%s](#%s)", schemaName, strings.ToLower(schemaName))
+			return fmt.Sprintf("[%s](#%s)", displayName, strings.ToLower(schemaName))
 		} else {
-			return schemaName
+			return displayName
 		}
 	}
 	switch tpe.Type {
@@ -339,6 +394,14 @@ func (tpe *KclOpenAPIType) isAnyType() bool {
 	return tpe.Type == Object && tpe.Properties == nil && tpe.AdditionalProperties == nil && tpe.Ref == "" && (tpe.KclExtensions == nil || tpe.KclExtensions.XKclUnionTypes == nil)
 }
 
+// schemaId returns the schema id of the schema type, which is the full type name of the schema
+func (tpe *KclOpenAPIType) schemaId() string {
+	if tpe.KclExtensions.XKclModelType.Import.Package != "" {
+		return fmt.Sprintf("%s.%s", tpe.KclExtensions.XKclModelType.Import.Package, tpe.KclExtensions.XKclModelType.Type)
+	}
+	return tpe.KclExtensions.XKclModelType.Type
+}
+
 func (tpe *KclOpenAPIType) GetSchemaPkgDir(base string) string {
 	return GetPkgDir(base, tpe.KclExtensions.XKclModelType.Import.Package)
 }
@@ -358,6 +421,42 @@ func (tpe *KclOpenAPIType) GetExtensionsMapping() map[string]interface{} {
 		if tpe.XKclDictKeyType != nil {
 			m[ExtensionKclDictKeyType] = tpe.XKclDictKeyType
 		}
+		if tpe.XKclChecks != nil {
+			m[ExtensionKclChecks] = tpe.XKclChecks
+		}
+		if tpe.XKclBaseSchema != "" {
+			m[ExtensionKclBaseSchema] = tpe.XKclBaseSchema
+		}
+		if tpe.XKclImportAlias != "" {
+			m[ExtensionKclImportAlias] = tpe.XKclImportAlias
+		}
+		if tpe.XKclAttributeGroups != nil {
+			m[ExtensionKclAttrGroups] = tpe.XKclAttributeGroups
+		}
+		if tpe.XKclUnit != "" {
+			m[ExtensionKclUnit] = tpe.XKclUnit
+		}
+		if tpe.XKclKind != "" {
+			m[ExtensionKclKind] = tpe.XKclKind
+		}
+		if tpe.XKclTypeExpr != "" {
+			m[ExtensionKclTypeExpr] = tpe.XKclTypeExpr
+		}
+		if tpe.XKclMixins != nil {
+			m[ExtensionKclMixins] = tpe.XKclMixins
+		}
+		if tpe.XKclAttributeOrder != nil {
+			m[ExtensionKclAttrOrder] = tpe.XKclAttributeOrder
+		}
+		if tpe.XKclEnum != "" {
+			m[ExtensionKclEnum] = tpe.XKclEnum
+		}
+		if tpe.XKclProtocol != "" {
+			m[ExtensionKclProtocol] = tpe.XKclProtocol
+		}
+		if tpe.XKclSchemaArgs != "" {
+			m[ExtensionKclSchemaArgs] = tpe.XKclSchemaArgs
+		}
 	}
 	return m
 }
@@ -376,6 +475,12 @@ func GetPkgDir(base string, pkgName string) string {
 
 // GetKclOpenAPIType converts the kcl.KclType(the representation of Type in KCL API) to KclOpenAPIType(the representation of Type in KCL Open API)
 func GetKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool) *KclOpenAPIType {
+	return getKclOpenAPIType(pkgPath, from, nested, readSchemaSource)
+}
+
+// getKclOpenAPIType converts the type with the source code of the schema files read by readSource, which parses the
+// check expressions, the kinds, the base schemas and the imports of the schemas
+func getKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool, readSource func(filename string) string) *KclOpenAPIType {
 	t := KclOpenAPIType{
 		Description: from.Description,
 		Default:     from.Default,
@@ -418,12 +523,12 @@ func GetKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool) *KclOpenA
 		return &t
 	case typList:
 		t.Type = Array
-		t.Items = GetKclOpenAPIType(pkgPath, from.Item, true)
+		t.Items = getKclOpenAPIType(pkgPath, from.Item, true, readSource)
 		return &t
 	case typDict:
 		t.Type = Object
-		t.AdditionalProperties = GetKclOpenAPIType(pkgPath, from.Item, true)
-		ty := GetKclOpenAPIType(pkgPath, from.Key, true)
+		t.AdditionalProperties = getKclOpenAPIType(pkgPath, from.Item, true, readSource)
+		ty := getKclOpenAPIType(pkgPath, from.Key, true, readSource)
 		if t.KclExtensions == nil {
 			t.KclExtensions = &KclExtensions{
 				XKclDictKeyType: ty,
@@ -444,7 +549,8 @@ func GetKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool) *KclOpenA
 		t.Description = from.SchemaDoc
 		t.Properties = make(map[string]*KclOpenAPIType, len(from.Properties))
 		for name, fromProp := range from.Properties {
-			t.Properties[name] = GetKclOpenAPIType(pkgPath, fromProp, true)
+			t.Properties[name] = getKclOpenAPIType(pkgPath, fromProp, true, readSource)
+			t.Properties[name].applyUnit()
 		}
 		t.Required = from.Required
 		packageName := PackageName(pkgPath, from)
@@ -462,6 +568,41 @@ func GetKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool) *KclOpenA
 		} else {
 			t.KclExtensions.XKclModelType = ty
 		}
+		source := readSource(from.Filename)
+		t.KclExtensions.XKclChecks = parseSchemaChecks(source, from.SchemaName)
+		t.KclExtensions.XKclKind = parseSchemaKind(source, from.SchemaName)
+		if base := baseSchemaId(pkgPath, from, source); base != "" {
+			t.KclExtensions.XKclBaseSchema = SchemaId2Ref(base)
+		}
+		if protocol := resolveSchemaId(pkgPath, from, source, parseMixinProtocol(source, from.SchemaName)); protocol != "" {
+			t.KclExtensions.XKclProtocol = SchemaId2Ref(protocol)
+		}
+		t.KclExtensions.XKclSchemaArgs = parseSchemaArguments(source, from.SchemaName)
+		for _, mixin := range parseSchemaMixins(source, from.SchemaName) {
+			if id := resolveSchemaId(pkgPath, from, source, mixin); id != "" {
+				t.KclExtensions.XKclMixins = append(t.KclExtensions.XKclMixins, SchemaId2Ref(id))
+			}
+		}
+		t.applyImportAliases(parseImports(source))
+		for _, attr := range parseAttributeTypes(source, from.SchemaName) {
+			t.KclExtensions.XKclAttributeOrder = append(t.KclExtensions.XKclAttributeOrder, attr.Name)
+			if prop, ok := t.Properties[attr.Name]; ok {
+				if prop.KclExtensions == nil {
+					prop.KclExtensions = &KclExtensions{}
+				}
+				prop.KclExtensions.XKclTypeExpr = attr.Expr
+				if alias := typeAliasName(attr.Expr); alias != "" && len(enumMembers(prop)) > 0 {
+					// the attribute type is an enum declared as a type alias of the literal union type
+					prop.KclExtensions.XKclEnum = resolveSchemaId(pkgPath, from, source, alias)
+				}
+			}
+		}
+		for _, name := range parseNullableAttributes(source, from.SchemaName) {
+			if prop, ok := t.Properties[name]; ok {
+				prop.Nullable = true
+			}
+		}
+		t.applyCheckConstraints()
 		t.Examples = make(map[string]KclExample, len(from.GetExamples()))
 		for name, example := range from.GetExamples() {
 			t.Examples[name] = KclExample{
@@ -473,11 +614,30 @@ func GetKclOpenAPIType(pkgPath string, from *kcl.KclType, nested bool) *KclOpenA
 		// todo externalDocs(see also)
 		return &t
 	case typUnion:
-		t.Type = Object
-		tps := make([]*KclOpenAPIType, len(from.UnionTypes))
-		for i, unionType := range from.UnionTypes {
-			tps[i] = GetKclOpenAPIType(pkgPath, unionType, true)
+		var tps []*KclOpenAPIType
+		for _, unionType := range from.UnionTypes {
+			if unionType.Type == typNone {
+				// None in the union type is tracked as the nullability of the type
+				t.Nullable = true
+				continue
+			}
+			tps = append(tps, getKclOpenAPIType(pkgPath, unionType, true, readSource))
+		}
+		if len(tps) == 1 {
+			// `T | None` is the nullable T
+			ty := tps[0]
+			ty.Description = t.Description
+			ty.Default = t.Default
+			ty.Nullable = true
+			if t.KclExtensions != nil {
+				if ty.KclExtensions == nil {
+					ty.KclExtensions = &KclExtensions{}
+				}
+				ty.KclExtensions.XKclDecorators = t.KclExtensions.XKclDecorators
+			}
+			return ty
 		}
+		t.Type = Object
 		if t.KclExtensions == nil {
 			t.KclExtensions = &KclExtensions{
 				XKclUnionTypes: tps,
@@ -0,0 +1,322 @@
+package gen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// XKclCheck defines an expression in the schema check block, carried by the `x-kcl-checks` extension
+type XKclCheck struct {
+	Expr      string `json:"expr"`                // the check expression
+	Condition string `json:"condition,omitempty"` // the `if` condition of the check expression
+	Message   string `json:"message,omitempty"`   // the error message of the check expression
+}
+
+// parseSchemaChecks extracts the check expressions of the schema from the KCL source code
+func parseSchemaChecks(source string, schemaName string) []*XKclCheck {
+	var exprLines []string
+	inCheck, checkIndent := false, 0
+	for _, line := range schemaBodyLines(source, schemaName) {
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if inCheck && indent <= checkIndent {
+			inCheck = false
+		}
+		if inCheck {
+			exprLines = append(exprLines, trimmed)
+			continue
+		}
+		if trimmed == "check:" {
+			inCheck, checkIndent = true, indent
+		}
+	}
+	var checks []*XKclCheck
+	for _, expr := range joinBracketLines(exprLines) {
+		checks = append(checks, splitCheckExpr(expr))
+	}
+	return checks
+}
+
+// joinBracketLines joins the lines of an expression across multiple lines in brackets
+func joinBracketLines(lines []string) []string {
+	var result []string
+	var current string
+	depth := 0
+	for _, line := range lines {
+		if depth > 0 {
+			current += " " + line
+		} else {
+			current = line
+		}
+		depth += bracketDepth(line)
+		if depth <= 0 {
+			result = append(result, current)
+			depth = 0
+		}
+	}
+	if depth > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+func bracketDepth(s string) int {
+	depth := 0
+	var quote rune
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// splitCheckExpr splits a check item like `expr if condition, "message"` into its parts
+func splitCheckExpr(item string) *XKclCheck {
+	check := &XKclCheck{Expr: item}
+	if i := lastTopLevelIndex(check.Expr, ","); i >= 0 {
+		if msg := strings.TrimSpace(check.Expr[i+1:]); strings.HasPrefix(msg, `"`) || strings.HasPrefix(msg, `'`) {
+			check.Message = strings.Trim(msg, `"'`)
+			check.Expr = strings.TrimSpace(check.Expr[:i])
+		}
+	}
+	if i := lastTopLevelIndex(check.Expr, " if "); i >= 0 {
+		check.Condition = strings.TrimSpace(check.Expr[i+len(" if "):])
+		check.Expr = strings.TrimSpace(check.Expr[:i])
+	}
+	return check
+}
+
+// lastTopLevelIndex returns the index of the last sep which is not in quotes or brackets
+func lastTopLevelIndex(s string, sep string) int {
+	last := -1
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			last = i
+		}
+	}
+	return last
+}
+
+// checkComparison is a binary comparison like `len(a) >= 1` in a check expression
+type checkComparison struct {
+	Left  string
+	Op    string
+	Right string
+}
+
+var comparisonOpRegexp = regexp.MustCompile(`\s*(<=|>=|==|<|>)\s*`)
+
+// parseComparisons parses a chained comparison expression like `1 <= len(a) <= 10` to binary comparisons
+func parseComparisons(expr string) []checkComparison {
+	operands := comparisonOpRegexp.Split(expr, -1)
+	ops := comparisonOpRegexp.FindAllStringSubmatch(expr, -1)
+	if len(ops) == 0 || len(operands) != len(ops)+1 {
+		return nil
+	}
+	comparisons := make([]checkComparison, len(ops))
+	for i, op := range ops {
+		comparisons[i] = checkComparison{
+			Left:  strings.TrimSpace(operands[i]),
+			Op:    op[1],
+			Right: strings.TrimSpace(operands[i+1]),
+		}
+	}
+	return comparisons
+}
+
+var flippedOps = map[string]string{"<": ">", "<=": ">=", ">": "<", ">=": "<=", "==": "=="}
+
+// intBounds returns the integer lower and upper bounds of the subject in the comparison
+func (c checkComparison) intBounds(subject string) (min *int, max *int) {
+	left, op, right := c.Left, c.Op, c.Right
+	if right == subject {
+		left, op, right = right, flippedOps[op], left
+	}
+	if left != subject {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(right)
+	if err != nil {
+		return nil, nil
+	}
+	switch op {
+	case ">":
+		n++
+		return &n, nil
+	case ">=":
+		return &n, nil
+	case "<":
+		n--
+		return nil, &n
+	case "<=":
+		return nil, &n
+	case "==":
+		return &n, &n
+	}
+	return nil, nil
+}
+
+// applyCheckConstraints derives the constraints of the schema attributes from the schema check expressions
+func (tpe *KclOpenAPIType) applyCheckConstraints() {
+	if tpe.KclExtensions == nil {
+		return
+	}
+	for _, check := range tpe.KclExtensions.XKclChecks {
+		if check.Condition != "" {
+			// the conditional checks do not constrain the attributes unconditionally
+			continue
+		}
+		group := parseAttributeGroup(check.Expr, func(name string) bool {
+			_, ok := tpe.Properties[name]
+			return ok
+		})
+		if group != nil {
+			tpe.KclExtensions.XKclAttributeGroups = append(tpe.KclExtensions.XKclAttributeGroups, group)
+			continue
+		}
+		for _, c := range parseComparisons(check.Expr) {
+			for name, prop := range tpe.Properties {
+				min, max := c.intBounds("len(" + name + ")")
+				if min == nil && max == nil {
+					continue
+				}
+				switch {
+				case prop.Type == Array:
+					prop.MinItems, prop.MaxItems = mergeBound(prop.MinItems, min), mergeBound(prop.MaxItems, max)
+				case prop.Type == Object && prop.AdditionalProperties != nil:
+					prop.MinProperties, prop.MaxProperties = mergeBound(prop.MinProperties, min), mergeBound(prop.MaxProperties, max)
+				}
+			}
+		}
+	}
+}
+
+func mergeBound(old *int, new *int) *int {
+	if new != nil {
+		return new
+	}
+	return old
+}
+
+// AttributeGroupKind is the kind of the constraint on a group of attributes
+type AttributeGroupKind string
+
+const (
+	ExactlyOne AttributeGroupKind = "exactlyOne"
+	AtMostOne  AttributeGroupKind = "atMostOne"
+	AtLeastOne AttributeGroupKind = "atLeastOne"
+)
+
+// XKclAttributeGroup defines a group of attributes of which exactly/at most/at least one is set, derived from the
+// schema check expressions and carried by the `x-kcl-attribute-groups` extension
+type XKclAttributeGroup struct {
+	Kind       AttributeGroupKind `json:"kind"`
+	Attributes []string           `json:"attributes"`
+}
+
+var (
+	identRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// countRegexp matches the count of the set attributes, such as `len([x for x in [a, b, c] if x])`
+	countRegexp = regexp.MustCompile(`^len\(\s*\[\s*([a-zA-Z_][a-zA-Z0-9_]*)\s+for\s+([a-zA-Z_][a-zA-Z0-9_]*)\s+in\s+\[([^\[\]]*)\]\s+if\s+([a-zA-Z_][a-zA-Z0-9_]*)(\s*!=\s*None|\s+is\s+not\s+None)?\s*\]\s*\)$`)
+)
+
+// parseAttributeGroup recognizes the check expressions on a group of attributes:
+//
+//	len([x for x in [a, b, c] if x]) == 1  # exactly one of a, b, c
+//	len([x for x in [a, b, c] if x]) <= 1  # at most one of a, b, c
+//	len([x for x in [a, b, c] if x]) >= 1  # at least one of a, b, c
+//	a or b or c                            # at least one of a, b, c
+//	not a or not b                         # at most one of a, b
+//	not (a and b)                          # at most one of a, b
+func parseAttributeGroup(expr string, isAttribute func(name string) bool) *XKclAttributeGroup {
+	allAttributes := func(names []string) bool {
+		if len(names) < 2 {
+			return false
+		}
+		for _, name := range names {
+			if !identRegexp.MatchString(name) || !isAttribute(name) {
+				return false
+			}
+		}
+		return true
+	}
+	if comparisons := parseComparisons(expr); len(comparisons) == 1 {
+		c := comparisons[0]
+		for _, subject := range []string{c.Left, c.Right} {
+			m := countRegexp.FindStringSubmatch(subject)
+			if m == nil || m[1] != m[2] || m[2] != m[4] {
+				continue
+			}
+			var names []string
+			for _, name := range strings.Split(m[3], ",") {
+				names = append(names, strings.TrimSpace(name))
+			}
+			if !allAttributes(names) {
+				return nil
+			}
+			min, max := c.intBounds(subject)
+			switch {
+			case min != nil && max != nil && *min == 1 && *max == 1:
+				return &XKclAttributeGroup{Kind: ExactlyOne, Attributes: names}
+			case min == nil && max != nil && *max == 1:
+				return &XKclAttributeGroup{Kind: AtMostOne, Attributes: names}
+			case min != nil && max == nil && *min == 1:
+				return &XKclAttributeGroup{Kind: AtLeastOne, Attributes: names}
+			}
+			return nil
+		}
+		return nil
+	}
+	if inner := strings.TrimSpace(strings.TrimPrefix(expr, "not")); strings.HasPrefix(expr, "not") && strings.HasPrefix(inner, "(") && strings.HasSuffix(inner, ")") {
+		names := splitTopLevel(strings.TrimSpace(inner[1:len(inner)-1]), " and ")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		if len(names) == 2 && allAttributes(names) {
+			return &XKclAttributeGroup{Kind: AtMostOne, Attributes: names}
+		}
+		return nil
+	}
+	operands := splitTopLevel(expr, " or ")
+	var names, negated []string
+	for _, operand := range operands {
+		operand = strings.TrimSpace(operand)
+		if name := strings.TrimPrefix(operand, "not "); name != operand {
+			negated = append(negated, strings.TrimSpace(name))
+		} else {
+			names = append(names, operand)
+		}
+	}
+	switch {
+	case len(negated) == 0 && allAttributes(names):
+		return &XKclAttributeGroup{Kind: AtLeastOne, Attributes: names}
+	case len(names) == 0 && len(negated) == 2 && allAttributes(negated):
+		return &XKclAttributeGroup{Kind: AtMostOne, Attributes: negated}
+	}
+	return nil
+}
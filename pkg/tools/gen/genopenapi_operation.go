@@ -0,0 +1,58 @@
+package gen
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	// DefaultOperationPathTemplate is the default path template of the operation stubs
+	DefaultOperationPathTemplate = "/{schema}"
+	oaiV3ComponentRef            = "#/components/schemas/"
+)
+
+// AddOperationStubs adds a stub `GET` and `PUT` path for each component schema of the open api v3 spec, which uses
+// the schema as the response and request body. The `{schema}` placeholder in the path template is replaced with the
+// schema id, and the DefaultOperationPathTemplate is used if the path template is empty.
+func AddOperationStubs(t *openapi3.T, pathTemplate string) {
+	if pathTemplate == "" {
+		pathTemplate = DefaultOperationPathTemplate
+	}
+	ids := make([]string, 0, len(t.Components.Schemas))
+	for id := range t.Components.Schemas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		ref := openapi3.NewSchemaRef(oaiV3ComponentRef+id, t.Components.Schemas[id].Value)
+		name := schemaShortName(id)
+		operationId := strcase.ToCamel(strings.ReplaceAll(id, ".", "_"))
+		path := strings.ReplaceAll(pathTemplate, "{schema}", id)
+
+		get := openapi3.NewOperation()
+		get.OperationID = "get" + operationId
+		get.Summary = fmt.Sprintf("Get the %s", name)
+		get.Responses = operationStubResponses(name, ref)
+		t.AddOperation(path, http.MethodGet, get)
+
+		put := openapi3.NewOperation()
+		put.OperationID = "put" + operationId
+		put.Summary = fmt.Sprintf("Create or replace the %s", name)
+		put.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(ref),
+		}
+		put.Responses = operationStubResponses(name, ref)
+		t.AddOperation(path, http.MethodPut, put)
+	}
+}
+
+// operationStubResponses returns the responses of the operation stub with the schema as the response body
+func operationStubResponses(name string, ref *openapi3.SchemaRef) *openapi3.Responses {
+	response := openapi3.NewResponse().WithDescription(fmt.Sprintf("The %s", name)).WithJSONSchemaRef(ref)
+	return openapi3.NewResponses(openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{Value: response}))
+}
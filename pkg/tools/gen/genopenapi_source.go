@@ -0,0 +1,315 @@
+package gen
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	kcl "kcl-lang.io/kcl-go"
+)
+
+// schemaStmtRegexp matches the declaration statements such as `schema Server[name: str](Base):` with the kind, the
+// name, the arguments, the base schema and the protocol of the mixin declared with `for`
+var schemaStmtRegexp = regexp.MustCompile(`^(schema|mixin|protocol)\s+([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:\[([^\]]*)\])?\s*(?:\(\s*([a-zA-Z_][a-zA-Z0-9_.]*)\s*\))?(?:\s*for\s+([a-zA-Z_][a-zA-Z0-9_.]*))?`)
+
+// readSchemaSource reads the source code of the schema file, returns an empty string if the file is not readable
+func readSchemaSource(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	return string(source)
+}
+
+// schemaBodyLines returns the lines of the schema body in the KCL source code, the docstrings, comments and
+// blank lines are skipped
+func schemaBodyLines(source string, schemaName string) []string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schemaName {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	var body []string
+	inDocString := false
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if inDocString {
+			if strings.Count(line, `"""`)%2 == 1 {
+				inDocString = false
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if len(line) == len(strings.TrimLeft(line, " \t")) {
+			// the end of the schema body
+			break
+		}
+		if strings.Count(line, `"""`)%2 == 1 {
+			inDocString = true
+			continue
+		}
+		body = append(body, line)
+	}
+	return body
+}
+
+var attributeStmtRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)(\?)?\s*:\s*(.+?)\s*(?:=.*)?$`)
+
+// attributeTypeExpr is the type annotation of a schema attribute as authored, such as `{str: [int | str]}`
+type attributeTypeExpr struct {
+	Name string
+	Expr string
+}
+
+// parseAttributeTypes returns the type annotations of the schema attributes in the declaration order
+func parseAttributeTypes(source string, schemaName string) []attributeTypeExpr {
+	lines := schemaBodyLines(source, schemaName)
+	if len(lines) == 0 {
+		return nil
+	}
+	attrIndent := len(lines[0]) - len(strings.TrimLeft(lines[0], " \t"))
+	var types []attributeTypeExpr
+	for _, line := range lines {
+		if len(line)-len(strings.TrimLeft(line, " \t")) != attrIndent {
+			continue
+		}
+		m := attributeStmtRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		types = append(types, attributeTypeExpr{Name: m[1], Expr: m[3]})
+	}
+	return types
+}
+
+// parseNullableAttributes returns the attributes of the schema whose type annotation accepts None, such as
+// `name?: str | None`
+func parseNullableAttributes(source string, schemaName string) []string {
+	var names []string
+	for _, t := range parseAttributeTypes(source, schemaName) {
+		for _, member := range splitTopLevel(t.Expr, "|") {
+			if strings.TrimSpace(member) == "None" {
+				names = append(names, t.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// splitTopLevel splits s by sep which is not in quotes or brackets
+func splitTopLevel(s string, sep string) []string {
+	var parts []string
+	for {
+		i := lastTopLevelIndex(s, sep)
+		if i < 0 {
+			break
+		}
+		parts = append([]string{s[i+len(sep):]}, parts...)
+		s = s[:i]
+	}
+	return append([]string{s}, parts...)
+}
+
+// typeNameRegexp matches the type names such as `Protocol` or `types.Protocol`
+var typeNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// typeAliasName returns the type name of the type annotation which is a single type name such as `Protocol`, or a
+// nullable type name such as `Protocol | None`, else an empty string
+func typeAliasName(expr string) string {
+	var names []string
+	for _, member := range splitTopLevel(expr, "|") {
+		if member = strings.TrimSpace(member); member != "None" {
+			names = append(names, member)
+		}
+	}
+	if len(names) != 1 || !typeNameRegexp.MatchString(names[0]) {
+		return ""
+	}
+	return names[0]
+}
+
+var importStmtRegexp = regexp.MustCompile(`^import\s+(\.*[a-zA-Z_][a-zA-Z0-9_.]*)(?:\s+as\s+([a-zA-Z_][a-zA-Z0-9_]*))?\s*(?:#.*)?$`)
+
+// parseImports returns the mapping from the import alias to the imported package path in the KCL source code.
+// The alias of an import statement without `as` is the last part of the package path
+func parseImports(source string) map[string]string {
+	imports := map[string]string{}
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		m := importStmtRegexp.FindStringSubmatch(strings.TrimRight(line, " \t"))
+		if m == nil {
+			continue
+		}
+		alias := m[2]
+		if alias == "" {
+			parts := strings.Split(m[1], ".")
+			alias = parts[len(parts)-1]
+		}
+		imports[alias] = m[1]
+	}
+	return imports
+}
+
+// parseSchemaBase returns the base schema as written in the schema statement, such as `Base` or `base.Base`
+func parseSchemaBase(source string, schemaName string) string {
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schemaName {
+			return m[4]
+		}
+	}
+	return ""
+}
+
+// parseSchemaArguments returns the arguments of the schema as written in the schema statement, such as
+// `name: str, age: int = 1` for `schema Person[name: str, age: int = 1]`
+func parseSchemaArguments(source string, schemaName string) string {
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schemaName {
+			return strings.TrimSpace(m[3])
+		}
+	}
+	return ""
+}
+
+// parseMixinProtocol returns the protocol of the mixin as written in the mixin statement, such as `NameProtocol`
+// for `mixin NameMixin for NameProtocol`
+func parseMixinProtocol(source string, schemaName string) string {
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schemaName {
+			return m[5]
+		}
+	}
+	return ""
+}
+
+// Kinds of the KCL declarations other than the schema
+const (
+	MixinKind    = "mixin"
+	ProtocolKind = "protocol"
+)
+
+// parseSchemaKind returns the kind of the declaration in the source code: `mixin` for the `mixin` declarations and
+// the schemas named with the `Mixin` suffix, `protocol` for the `protocol` declarations, and empty for the schemas
+func parseSchemaKind(source string, schemaName string) string {
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if m := schemaStmtRegexp.FindStringSubmatch(line); m != nil && m[2] == schemaName {
+			switch {
+			case m[1] == MixinKind || strings.HasSuffix(schemaName, "Mixin"):
+				return MixinKind
+			case m[1] == ProtocolKind:
+				return ProtocolKind
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// mixinStmtRegexp matches the mixin statement of the schema body such as `mixin [NameMixin, m.AgeMixin]`
+var mixinStmtRegexp = regexp.MustCompile(`^mixin\s*\[([^\]]*)\]`)
+
+// parseSchemaMixins returns the mixins of the schema as written in the mixin statement, such as `NameMixin` or
+// `m.AgeMixin`
+func parseSchemaMixins(source string, schemaName string) []string {
+	var mixins []string
+	for _, line := range schemaBodyLines(source, schemaName) {
+		m := mixinStmtRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		for _, name := range strings.Split(m[1], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				mixins = append(mixins, name)
+			}
+		}
+	}
+	return mixins
+}
+
+// baseSchemaId resolves the schema id of the base schema. The base schema declared in the same package shares
+// the package name of the schema, and the base schema referenced by an import alias is resolved by the import
+// statements in the schema file
+func baseSchemaId(pkgPath string, from *kcl.KclType, source string) string {
+	return resolveSchemaId(pkgPath, from, source, parseSchemaBase(source, from.SchemaName))
+}
+
+// resolveSchemaId resolves the schema id of the schema name as written in the schema file of the type, such as the
+// base schema and the mixins
+func resolveSchemaId(pkgPath string, from *kcl.KclType, source string, base string) string {
+	if base == "" {
+		return ""
+	}
+	pkgName := PackageName(pkgPath, from)
+	i := strings.LastIndex(base, ".")
+	if i < 0 {
+		if pkgName == "" {
+			return base
+		}
+		return pkgName + "." + base
+	}
+	importPath, ok := parseImports(source)[base[:i]]
+	if !ok {
+		return ""
+	}
+	if strings.HasPrefix(importPath, ".") {
+		// relative import from the current package
+		importPath = strings.TrimLeft(importPath, ".")
+		if pkgName != "" {
+			importPath = pkgName + "." + importPath
+		}
+	}
+	return importPath + base[i:]
+}
+
+// applyImportAliases records the import alias on the references to the schemas in other packages, so the
+// references are displayed as they are written in the source code, such as `p.Person` for `import models as p`.
+// The alias is only recorded when it differs from the last part of the package path
+func (tpe *KclOpenAPIType) applyImportAliases(imports map[string]string) {
+	if len(imports) == 0 {
+		return
+	}
+	var apply func(t *KclOpenAPIType)
+	apply = func(t *KclOpenAPIType) {
+		if t == nil {
+			return
+		}
+		if t.Ref != "" {
+			id := Ref2SchemaId(t.Ref)
+			i := strings.LastIndex(id, ".")
+			if i < 0 {
+				return
+			}
+			pkgName := id[:i]
+			for alias, importPath := range imports {
+				if importPath == pkgName && alias != pkgName[strings.LastIndex(pkgName, ".")+1:] {
+					if t.KclExtensions == nil {
+						t.KclExtensions = &KclExtensions{}
+					}
+					t.KclExtensions.XKclImportAlias = alias
+				}
+			}
+			return
+		}
+		apply(t.Items)
+		apply(t.AdditionalProperties)
+		if t.KclExtensions != nil {
+			for _, u := range t.KclExtensions.XKclUnionTypes {
+				apply(u)
+			}
+		}
+	}
+	for _, prop := range tpe.Properties {
+		apply(prop)
+	}
+}
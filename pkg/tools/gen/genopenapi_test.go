@@ -1,11 +1,14 @@
 package gen
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	assert2 "github.com/stretchr/testify/assert"
+	kcl "kcl-lang.io/kcl-go"
 )
 
 func TestExportOpenAPIV3Spec(t *testing.T) {
@@ -24,7 +27,7 @@ func TestExportOpenAPIV3Spec(t *testing.T) {
 	}
 	got := string(json)
 
-	expect := `{"default":"","description":"AppConfiguration is a developer-centric definition that describes how to run an Application. This application model builds upon a decade of experience at AntGroup running super large scale internal developer platform, combined with best-of-breed ideas and practices from the community.","example":{"Default example":{"value":"# Instantiate an App with a long-running service and its image is \"nginx:v1\"\n\nimport models.schema.v1 as ac\nimport models.schema.v1.workload as wl\nimport models.schema.v1.workload.container as c\n\nappConfiguration = ac.AppConfiguration {\n    workload: wl.Service {\n        containers: {\n            \"nginx\": c.Container {\n                image: \"nginx:v1\"\n            }\n        }\n    }\n}"}},"properties":{"annotations":{"additionalProperties":{"default":"","type":"string"},"default":"","description":"Annotations are key/value pairs that attach arbitrary non-identifying metadata to resources.","type":"object","x-kcl-decorators":[{"name":"info","keywords":{"hidden":"True"}}],"x-kcl-dict-key-type":{"type":"string"}},"database":{"$ref":"#/definitions/models.schema.v1.accessories.Database"},"labels":{"additionalProperties":{"default":"","type":"string"},"default":"","description":"Labels can be used to attach arbitrary metadata as key-value pairs to resources.","type":"object","x-kcl-decorators":[{"name":"info","keywords":{"hidden":"True"}}],"x-kcl-dict-key-type":{"type":"string"}},"monitoring":{"$ref":"#/definitions/models.schema.v1.monitoring.Prometheus"},"opsRule":{"$ref":"#/definitions/models.schema.v1.trait.OpsRule"},"workload":{"default":"","description":"Workload defines how to run your application code. Currently supported workload profile\nincludes Service and Job.","type":"object","x-kcl-union-types":[{"description":"Service is a kind of workload profile that describes how to run your application code. This is typically used for long-running web applications that should \"never\" go down, and handle short-lived latency-sensitive web requests, or events.","ref":"#/definitions/models.schema.v1.workload.Service"},{"description":"Job is a kind of workload profile that describes how to run your application code. This is typically used for tasks that take from a few seconds to a few days to complete.","ref":"#/definitions/models.schema.v1.workload.Job"}]}},"required":["workload"],"type":"object","x-kcl-type":{"type":"AppConfiguration","import":{"package":"models.schema.v1","alias":"app_configuration.k"}}}`
+	expect := `{"default":"","description":"AppConfiguration is a developer-centric definition that describes how to run an Application. This application model builds upon a decade of experience at AntGroup running super large scale internal developer platform, combined with best-of-breed ideas and practices from the community.","example":{"Default example":{"value":"# Instantiate an App with a long-running service and its image is \"nginx:v1\"\n\nimport models.schema.v1 as ac\nimport models.schema.v1.workload as wl\nimport models.schema.v1.workload.container as c\n\nappConfiguration = ac.AppConfiguration {\n    workload: wl.Service {\n        containers: {\n            \"nginx\": c.Container {\n                image: \"nginx:v1\"\n            }\n        }\n    }\n}"}},"properties":{"annotations":{"additionalProperties":{"default":"","type":"string"},"default":"","description":"Annotations are key/value pairs that attach arbitrary non-identifying metadata to resources.","type":"object","x-kcl-decorators":[{"name":"info","keywords":{"hidden":"True"}}],"x-kcl-dict-key-type":{"type":"string"}},"database":{"$ref":"#/definitions/models.schema.v1.accessories.Database"},"labels":{"additionalProperties":{"default":"","type":"string"},"default":"","description":"Labels can be used to attach arbitrary metadata as key-value pairs to resources.","type":"object","x-kcl-decorators":[{"name":"info","keywords":{"hidden":"True"}}],"x-kcl-dict-key-type":{"type":"string"}},"monitoring":{"$ref":"#/definitions/models.schema.v1.monitoring.Prometheus"},"opsRule":{"$ref":"#/definitions/models.schema.v1.trait.OpsRule"},"workload":{"default":"","description":"Workload defines how to run your application code. Currently supported workload profile\nincludes Service and Job.","type":"object","x-kcl-union-types":[{"description":"Service is a kind of workload profile that describes how to run your application code. This is typically used for long-running web applications that should \"never\" go down, and handle short-lived latency-sensitive web requests, or events.","ref":"#/definitions/models.schema.v1.workload.Service","x-kcl-import-alias":"wl"},{"description":"Job is a kind of workload profile that describes how to run your application code. This is typically used for tasks that take from a few seconds to a few days to complete.","ref":"#/definitions/models.schema.v1.workload.Job","x-kcl-import-alias":"wl"}]}},"required":["workload"],"type":"object","x-kcl-type":{"type":"AppConfiguration","import":{"package":"models.schema.v1","alias":"app_configuration.k"}}}`
 
 	assert2.Equal(t, expect, got)
 }
@@ -93,3 +96,201 @@ func TestExportSwaggerV2Spec(t *testing.T) {
 
 	assert2.Equal(t, expect, got)
 }
+
+func TestParseSchemaChecks(t *testing.T) {
+	source := `schema Base:
+    name: str
+
+schema Server(Base):
+    """Server is a server.
+
+    check:
+        not a check block
+    """
+    hosts: [str]
+    ports: [int]
+    labels: {str:str}
+
+    check:
+        len(hosts) > 0, "hosts must not be empty"
+        1 <= len(ports) <= 10 if ports
+        all k in labels {
+            k != ""
+        } if labels, "label keys must not be empty"
+        len(labels) <= 5
+
+schema Other:
+    check:
+        len(hosts) > 0
+`
+	checks := parseSchemaChecks(source, "Server")
+	assert2.Equal(t, []*XKclCheck{
+		{Expr: "len(hosts) > 0", Message: "hosts must not be empty"},
+		{Expr: "1 <= len(ports) <= 10", Condition: "ports"},
+		{Expr: `all k in labels { k != "" }`, Condition: "labels", Message: "label keys must not be empty"},
+		{Expr: "len(labels) <= 5"},
+	}, checks)
+
+	tpe := &KclOpenAPIType{
+		Type: Object,
+		Properties: map[string]*KclOpenAPIType{
+			"hosts":  {Type: Array, Items: &KclOpenAPIType{Type: String}},
+			"ports":  {Type: Array, Items: &KclOpenAPIType{Type: Integer}},
+			"labels": {Type: Object, AdditionalProperties: &KclOpenAPIType{Type: String}},
+		},
+		KclExtensions: &KclExtensions{XKclChecks: checks},
+	}
+	tpe.applyCheckConstraints()
+	one, five := 1, 5
+	assert2.Equal(t, &one, tpe.Properties["hosts"].MinItems)
+	assert2.Nil(t, tpe.Properties["hosts"].MaxItems)
+	// the bounds of the conditional checks are not applied
+	assert2.Nil(t, tpe.Properties["ports"].MinItems)
+	assert2.Nil(t, tpe.Properties["ports"].MaxItems)
+	assert2.Nil(t, tpe.Properties["labels"].MinProperties)
+	assert2.Equal(t, &five, tpe.Properties["labels"].MaxProperties)
+}
+
+func TestBaseSchemaId(t *testing.T) {
+	source := `import models.base
+import models.core as c
+import .mixins
+
+schema Server(Base):
+    port: int
+
+schema Service [name: str] (c.Workload):
+    port: int
+
+schema Job(base.Base):
+    cmd: str
+
+schema Cron(mixins.Schedule):
+    cron: str
+
+schema Pod:
+    image: str
+`
+	from := func(name string) *kcl.KclType {
+		return &kcl.KclType{SchemaName: name, PkgPath: "__main__"}
+	}
+	assert2.Equal(t, map[string]string{"base": "models.base", "c": "models.core", "mixins": ".mixins"}, parseImports(source))
+	assert2.Equal(t, "app.Base", baseSchemaId("app", from("Server"), source))
+	assert2.Equal(t, "models.core.Workload", baseSchemaId("app", from("Service"), source))
+	assert2.Equal(t, "models.base.Base", baseSchemaId("app", from("Job"), source))
+	assert2.Equal(t, "app.mixins.Schedule", baseSchemaId("app", from("Cron"), source))
+	assert2.Equal(t, "", baseSchemaId("app", from("Pod"), source))
+	assert2.Equal(t, "Base", baseSchemaId(".", from("Server"), source))
+}
+
+func TestImportAliases(t *testing.T) {
+	source := `import models.person as p
+import models.pet
+import models.base as base
+`
+	tpe := &KclOpenAPIType{
+		Type: Object,
+		Properties: map[string]*KclOpenAPIType{
+			"owner":   {Ref: SchemaId2Ref("models.person.Person")},
+			"friends": {Type: Array, Items: &KclOpenAPIType{Ref: SchemaId2Ref("models.person.Person")}},
+			"pet":     {Ref: SchemaId2Ref("models.pet.Pet")},
+			"base":    {Ref: SchemaId2Ref("models.base.Base")},
+			"local":   {Ref: SchemaId2Ref("Local")},
+		},
+	}
+	tpe.applyImportAliases(parseImports(source))
+	assert2.Equal(t, "[p.Person](#person)", tpe.Properties["owner"].GetKclTypeName(false, true, false))
+	assert2.Equal(t, "[p.Person]", tpe.Properties["friends"].GetKclTypeName(false, false, false))
+	assert2.Equal(t, "[Pet](#pet)", tpe.Properties["pet"].GetKclTypeName(false, true, false))
+	assert2.Equal(t, "Base", tpe.Properties["base"].GetKclTypeName(false, false, false))
+	assert2.Equal(t, "Local", tpe.Properties["local"].GetKclTypeName(false, false, false))
+}
+
+func TestNullableUnionType(t *testing.T) {
+	got := GetKclOpenAPIType(".", &kcl.KclType{
+		Type:        "union",
+		Description: "The name.",
+		UnionTypes:  []*kcl.KclType{{Type: "str"}, {Type: "NoneType"}},
+	}, true)
+	assert2.Equal(t, &KclOpenAPIType{Type: String, Description: "The name.", Nullable: true}, got)
+
+	got = GetKclOpenAPIType(".", &kcl.KclType{
+		Type:       "union",
+		UnionTypes: []*kcl.KclType{{Type: "str"}, {Type: "int"}, {Type: "NoneType"}},
+	}, true)
+	assert2.True(t, got.Nullable)
+	assert2.Equal(t, "str | int", got.GetKclTypeName(false, false, false))
+}
+
+func TestParseAttributeGroup(t *testing.T) {
+	isAttribute := func(name string) bool {
+		return name == "a" || name == "b" || name == "c"
+	}
+	cases := []struct {
+		expr   string
+		expect *XKclAttributeGroup
+	}{
+		{"len([x for x in [a, b, c] if x]) == 1", &XKclAttributeGroup{Kind: ExactlyOne, Attributes: []string{"a", "b", "c"}}},
+		{"1 == len([_x for _x in [a, b] if _x != None])", &XKclAttributeGroup{Kind: ExactlyOne, Attributes: []string{"a", "b"}}},
+		{"len([x for x in [a, b, c] if x]) <= 1", &XKclAttributeGroup{Kind: AtMostOne, Attributes: []string{"a", "b", "c"}}},
+		{"len([x for x in [a, b] if x is not None]) < 2", &XKclAttributeGroup{Kind: AtMostOne, Attributes: []string{"a", "b"}}},
+		{"not a or not b", &XKclAttributeGroup{Kind: AtMostOne, Attributes: []string{"a", "b"}}},
+		{"not (a and b)", &XKclAttributeGroup{Kind: AtMostOne, Attributes: []string{"a", "b"}}},
+		{"len([x for x in [a, b, c] if x]) > 0", &XKclAttributeGroup{Kind: AtLeastOne, Attributes: []string{"a", "b", "c"}}},
+		{"a or b or c", &XKclAttributeGroup{Kind: AtLeastOne, Attributes: []string{"a", "b", "c"}}},
+		{"len([x for x in [a, b, c] if x]) == 2", nil},
+		{"len([x for x in [a, d] if x]) == 1", nil},
+		{"not a or not b or not c", nil},
+		{"a or len(b) > 0", nil},
+		{"a", nil},
+	}
+	for _, c := range cases {
+		assert2.Equal(t, c.expect, parseAttributeGroup(c.expr, isAttribute), c.expr)
+	}
+
+	tpe := &KclOpenAPIType{
+		Type: Object,
+		Properties: map[string]*KclOpenAPIType{
+			"a": {Type: String}, "b": {Type: String}, "c": {Type: String},
+		},
+		KclExtensions: &KclExtensions{XKclChecks: parseSchemaChecks(`schema S:
+    a?: str
+    b?: str
+    c?: str
+
+    check:
+        a or b, "a or b is required"
+        not b or not c if a
+`, "S")},
+	}
+	tpe.applyCheckConstraints()
+	assert2.Equal(t, []*XKclAttributeGroup{{Kind: AtLeastOne, Attributes: []string{"a", "b"}}}, tpe.KclExtensions.XKclAttributeGroups)
+}
+
+func TestAddOperationStubs(t *testing.T) {
+	spec := &SwaggerV2Spec{
+		Info: SpecInfo{Title: "models", Version: "0.1.0"},
+		Definitions: map[string]*KclOpenAPIType{
+			"models.Server": newTestSchema("Server", "", []string{"host"}, map[string]*KclOpenAPIType{
+				"host": {Type: String},
+				"port": {Type: Integer, Format: Int64},
+			}),
+		},
+	}
+	doc := SwaggerV2ToOpenAPIV3Spec(spec)
+	AddOperationStubs(doc, "")
+	item := doc.Paths.Value("/models.Server")
+	if assert2.NotNil(t, item) {
+		assert2.Equal(t, "getModelsServer", item.Get.OperationID)
+		assert2.Equal(t, "#/components/schemas/models.Server", item.Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref)
+		assert2.Equal(t, "putModelsServer", item.Put.OperationID)
+		assert2.Equal(t, "#/components/schemas/models.Server", item.Put.RequestBody.Value.Content["application/json"].Schema.Ref)
+	}
+	// the exported schemas have empty string defaults which are not checked here
+	assert2.NoError(t, doc.Validate(context.Background(), openapi3.DisableSchemaDefaultsValidation()))
+
+	doc = SwaggerV2ToOpenAPIV3Spec(spec)
+	AddOperationStubs(doc, "/api/v1/configs/{schema}")
+	assert2.NotNil(t, doc.Paths.Value("/api/v1/configs/models.Server"))
+	assert2.Equal(t, 1, doc.Paths.Len())
+}
@@ -0,0 +1,23 @@
+package gen
+
+import "regexp"
+
+// unitLiteralRegexp matches the KCL number literals with the unit suffix such as `1Gi` and `500m`
+var unitLiteralRegexp = regexp.MustCompile(`^[0-9]+(n|u|m|k|K|M|G|T|P|Ki|Mi|Gi|Ti|Pi)$`)
+
+// isUnitLiteral checks if the KCL literal is a number with the unit suffix
+func isUnitLiteral(lit string) bool {
+	return unitLiteralRegexp.MatchString(lit)
+}
+
+// applyUnit records the unit suffix of the quantity default such as `Gi` of `1Gi` in the x-kcl-unit extension
+func (tpe *KclOpenAPIType) applyUnit() {
+	m := unitLiteralRegexp.FindStringSubmatch(tpe.Default)
+	if m == nil {
+		return
+	}
+	if tpe.KclExtensions == nil {
+		tpe.KclExtensions = &KclExtensions{}
+	}
+	tpe.KclExtensions.XKclUnit = m[1]
+}
@@ -0,0 +1,61 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Logger is the logger of the generation diagnostics. The fields are the key-value pairs such as
+// `"package", "models"`, so the logger can be adapted to a structured logger.
+type Logger interface {
+	Debug(msg string, fields ...interface{})
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+}
+
+// stdLogger is the default logger which prints the info messages to the stdout and the warning messages to the
+// stderr, and drops the debug messages
+type stdLogger struct{}
+
+var defaultLogger Logger = stdLogger{}
+
+func (stdLogger) Debug(msg string, fields ...interface{}) {}
+
+func (stdLogger) Info(msg string, fields ...interface{}) {
+	fmt.Println(logLine(msg, fields))
+}
+
+func (stdLogger) Warn(msg string, fields ...interface{}) {
+	fmt.Fprintf(os.Stderr, "[Warn] %s\n", logLine(msg, fields))
+}
+
+// logLine renders the message followed by the fields in `key=value` form
+func logLine(msg string, fields []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 < len(fields) {
+			fmt.Fprintf(&b, " %v=%v", fields[i], fields[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", fields[i])
+		}
+	}
+	return b.String()
+}
+
+// logger returns the logger of the context, which defaults to the standard logger
+func (g *GenContext) logger() Logger {
+	if g.Logger == nil {
+		return defaultLogger
+	}
+	return g.Logger
+}
+
+// logger returns the logger of the generator, which defaults to the standard logger
+func (k *kclGenerator) logger() Logger {
+	if k.opts.Logger == nil {
+		return defaultLogger
+	}
+	return k.opts.Logger
+}
@@ -23,6 +23,7 @@ const (
 	typAny              = "any"
 	typUnion            = "union"
 	typNumberMultiplier = "number_multiplier"
+	typNone             = "NoneType"
 )
 
 func getKclTypeName(typ *pb.KclType) string {
@@ -57,6 +58,8 @@ func getKclTypeName(typ *pb.KclType) string {
 
 	case typNumberMultiplier:
 		return "units.NumberMultiplier"
+	case typNone:
+		return "None"
 
 	default:
 		panic(fmt.Sprintf("ERR: unknown '%v', json = %v\n", typ.Type, jsonString(typ)))
@@ -198,6 +201,9 @@ type indexSignature struct {
 type data struct {
 	Key   string
 	Value interface{}
+	// Type is the type of the value which can not be inferred from the data, such as `any` of the null values, and
+	// is rendered as a trailing comment as the config entries have no type annotations
+	Type string
 }
 
 type config struct {